@@ -0,0 +1,13 @@
+// Package assets bundles the game's images, tilesets, and sounds into the
+// compiled binary via go:embed, so it can run from a single executable
+// with no assets/ directory alongside it. Callers that already load from
+// disk (see scenes.AssetStore, scenes.AudioRegistry) fall back to FS when
+// a path isn't found on disk, so the embedded copies only matter for
+// distribution - nothing has to change for local development with real
+// files on disk.
+package assets
+
+import "embed"
+
+//go:embed images audio tilesets
+var FS embed.FS
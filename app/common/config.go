@@ -26,6 +26,107 @@ type Config struct {
 			Fullscreen bool `yaml:"fullscreen"`
 		} `yaml:"window"`
 	} `yaml:"render"`
+
+	Audio struct {
+		Volume float64 `yaml:"volume"`
+	} `yaml:"audio"`
+
+	ComfyUI struct {
+		// BaseURL is used as the sole backend whenever Backends is empty,
+		// so existing single-instance configs keep working unchanged.
+		BaseURL        string `yaml:"base_url"`
+		WorkflowFolder string `yaml:"workflow_folder"`
+		// OutputDir is where generated images and the job history index
+		// (jobs.json) are persisted, so the gallery survives restarts.
+		OutputDir string `yaml:"output_dir"`
+		// Backends lists multiple ComfyUI instances for ComfyUIPool to
+		// spread generation requests across. Left empty, the pool falls
+		// back to a single backend built from BaseURL above.
+		Backends []ComfyUIBackendConfig `yaml:"backends"`
+		// SchedulingPolicy selects how ComfyUIPool picks a backend for
+		// each request: "least_busy" (default), "round_robin", or
+		// "sticky". Unset or unrecognized falls back to "least_busy".
+		SchedulingPolicy string `yaml:"scheduling_policy"`
+	} `yaml:"comfyui"`
+
+	// Theme configures ComfyUIScene's look without recompiling. Every
+	// field is optional - anything left blank falls back to the scene's
+	// original hard-coded look, so an absent `theme:` section behaves
+	// exactly as before this setting existed.
+	Theme struct {
+		Background struct {
+			Top    string `yaml:"top"` // hex color, e.g. "#28324d"
+			Bottom string `yaml:"bottom"`
+		} `yaml:"background"`
+		Accent        string `yaml:"accent"`          // hex color for selection highlights and spinner dots
+		BorderColor   string `yaml:"border_color"`    // hex color for box borders
+		InputBoxColor string `yaml:"input_box_color"` // hex color for the prompt input box fill
+		InputOpacity  int    `yaml:"input_opacity"`   // 0-255 alpha applied on top of InputBoxColor
+		ParticleCount int    `yaml:"particle_count"`
+		Font          struct {
+			Family string `yaml:"family"` // reserved: only the built-in bitmap atlas is supported today
+			Size   int    `yaml:"size"`   // integer scale factor for the bitmap font, see ui.TextInputConfig.Scale
+		} `yaml:"font"`
+	} `yaml:"theme"`
+
+	// Dungeon configures DungeonScene's tilemap.
+	Dungeon struct {
+		// MapPath is the .tmx file DungeonScene loads, resolved relative
+		// to the process's working directory like every other
+		// "assets/..." path in this config. Left blank, DungeonScene
+		// falls back to its bundled sample map. ComfyUI-generated room
+		// layouts can be exported as TMX and pointed to here with no code
+		// change.
+		MapPath string `yaml:"map_path"`
+	} `yaml:"dungeon"`
+
+	// Net configures the online sandbox (ScenePickerOnline / app/net).
+	Net struct {
+		// ListenAddr is the address Server.ListenAndServe binds when
+		// hosting, e.g. ":9000". Left blank, ScenePickerOnline falls back
+		// to DefaultListenAddr.
+		ListenAddr string `yaml:"listen_addr"`
+		// ServerAddr is the address Client.Dial connects to when
+		// joining, e.g. "ws://localhost:9000/ws". Left blank,
+		// ScenePickerOnline falls back to DefaultServerAddr.
+		ServerAddr string `yaml:"server_addr"`
+	} `yaml:"net"`
+
+	// Comfy holds named generation presets, so designers can add or tune
+	// workflows from YAML instead of recompiling ComfyUIScene.
+	Comfy struct {
+		// DefaultPreset selects which entry of Presets is active on first
+		// launch. If unset or unknown, the scene falls back to whichever
+		// preset name sorts first.
+		DefaultPreset string                    `yaml:"default_preset"`
+		Presets       map[string]WorkflowPreset `yaml:"presets"`
+	} `yaml:"comfy"`
+}
+
+// ComfyUIBackendConfig is one entry of Config.ComfyUI.Backends: a single
+// ComfyUI instance's address for services.ComfyUIPool to route requests to.
+type ComfyUIBackendConfig struct {
+	BaseURL string `yaml:"base_url"`
+}
+
+// Seed policies recognized by WorkflowPreset.SeedPolicy.
+const (
+	SeedPolicyFixed  = "fixed"  // always submit WorkflowPreset.Seed as-is
+	SeedPolicyRandom = "random" // draw a new seed for every generation
+)
+
+// WorkflowPreset is one named entry under Config.Comfy.Presets, bundling a
+// workflow file with the generation defaults ComfyUIScene applies while
+// that preset is active.
+type WorkflowPreset struct {
+	Workflow       string `yaml:"workflow"`
+	Steps          int    `yaml:"steps"`
+	SeedPolicy     string `yaml:"seed_policy"` // SeedPolicyFixed or SeedPolicyRandom
+	Seed           int    `yaml:"seed"`
+	Size           int    `yaml:"size"`
+	Ratio          string `yaml:"ratio"`
+	NegativePrompt string `yaml:"negative_prompt"`
+	Sampler        string `yaml:"sampler"`
 }
 
 func LoadConfig(path string) (*Config, error) {
@@ -40,3 +141,21 @@ func LoadConfig(path string) (*Config, error) {
 	}
 	return &cfg, nil
 }
+
+// Reload re-reads path and replaces every field of c in place, so anything
+// holding a *Config (e.g. Deps.Config) picks up the change without having
+// to re-fetch the pointer. See WatchReloadOnSIGHUP for the usual trigger.
+//
+// This isn't synchronized against concurrent reads of c's fields - config
+// values are read directly all over the codebase - so a reader could see
+// a mix of old and new values during the brief window the swap happens
+// in. That's an acceptable trade-off for a designer-facing tuning knob;
+// nothing gated on it is safety- or money-critical.
+func (c *Config) Reload(path string) error {
+	next, err := LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	*c = *next
+	return nil
+}
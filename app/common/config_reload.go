@@ -0,0 +1,31 @@
+//go:build !(js && wasm)
+
+package common
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// WatchReloadOnSIGHUP starts a background goroutine that reloads c from
+// path every time the process receives SIGHUP, so designers can tweak
+// theme and workflow presets without restarting the game. It's a no-op on
+// js/wasm builds (see config_reload_web.go), since there's no process to
+// signal in a browser.
+func (c *Config) WatchReloadOnSIGHUP(path string) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+
+	go func() {
+		for range sighup {
+			if err := c.Reload(path); err != nil {
+				log.WithError(err).WithField("path", path).Error("Failed to reload config on SIGHUP")
+				continue
+			}
+			log.WithField("path", path).Info("Reloaded config on SIGHUP")
+		}
+	}()
+}
@@ -0,0 +1,8 @@
+//go:build js && wasm
+
+package common
+
+// WatchReloadOnSIGHUP is a no-op on js/wasm builds: syscall.SIGHUP doesn't
+// exist in the browser, and there's no config file on disk to reload
+// anyway (see config_reload.go for the native implementation).
+func (c *Config) WatchReloadOnSIGHUP(path string) {}
@@ -0,0 +1,296 @@
+// Package console implements an in-game developer console: a
+// backtick-toggled text overlay that reads typed input via
+// ebiten.AppendInputChars and dispatches completed lines to registered
+// Cvars and commands. It's how the physics demos' tunables (ball
+// density, gravity strength, trail length, ...) get adjusted at runtime
+// instead of being recompiled-in constants.
+package console
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// maxLogLines bounds how many past lines Console keeps, so a long
+// session's scrollback doesn't grow the overlay (or its memory) forever.
+const maxLogLines = 100
+
+// Cvar is a single named, console-settable value. Get/Set read and write
+// straight through to whatever field registered it - Console never owns
+// the value itself, so `set gravity.g 5` takes effect the instant Set
+// runs. See NewBoolCvar/NewFloatCvar for the common cases.
+type Cvar struct {
+	Name string
+	Get  func() string
+	Set  func(value string) error
+}
+
+// NewBoolCvar wraps *target as a Cvar accepting "true"/"false" (and
+// "1"/"0", matching strconv.ParseBool).
+func NewBoolCvar(name string, target *bool) *Cvar {
+	return &Cvar{
+		Name: name,
+		Get:  func() string { return strconv.FormatBool(*target) },
+		Set: func(value string) error {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("%s: expected a bool, got %q", name, value)
+			}
+			*target = b
+			return nil
+		},
+	}
+}
+
+// NewFloatCvar wraps *target as a Cvar accepting any float64 literal.
+func NewFloatCvar(name string, target *float64) *Cvar {
+	return &Cvar{
+		Name: name,
+		Get:  func() string { return strconv.FormatFloat(*target, 'g', -1, 64) },
+		Set: func(value string) error {
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("%s: expected a number, got %q", name, value)
+			}
+			*target = f
+			return nil
+		},
+	}
+}
+
+// CvarSource is implemented by scenes that expose internal tunables to
+// the console (see BallsScene.Cvars/GravityScene.Cvars). Console calls it
+// once, each time SetActiveScene is given a new scene, rather than every
+// frame.
+type CvarSource interface {
+	Cvars() map[string]*Cvar
+}
+
+// Spawner is implemented by scenes that support the console's
+// `spawn <n>` command.
+type Spawner interface {
+	Spawn(n int)
+}
+
+// Clearer is implemented by scenes that support the console's `clear`
+// command.
+type Clearer interface {
+	Clear()
+}
+
+// CommandFunc handles a command's arguments (the tokens after its name),
+// returning an error to surface in the console log.
+type CommandFunc func(args []string) error
+
+// Console is a backtick-toggled command overlay. It owns no game state of
+// its own beyond its log/input buffer - scene tunables and host commands
+// (scene switching, screenshots, ...) are wired in via RegisterCommand and
+// SetActiveScene by whoever constructs it (see render.NewGame).
+type Console struct {
+	visible bool
+	input   []rune
+	log     []string
+
+	globalCvars map[string]*Cvar
+	cvars       map[string]*Cvar
+	activeScene interface{}
+	commands    map[string]CommandFunc
+}
+
+// New creates an empty Console with no commands or active scene yet.
+func New() *Console {
+	return &Console{
+		globalCvars: make(map[string]*Cvar),
+		cvars:       make(map[string]*Cvar),
+		commands:    make(map[string]CommandFunc),
+	}
+}
+
+// RegisterCommand adds a named command, invoked as `name arg1 arg2 ...`.
+// It's for host-level commands (scene switching, screenshots) that don't
+// belong to any one scene; see Spawner/Clearer for the scene-scoped
+// `spawn`/`clear` commands, which are handled directly by Console.
+func (c *Console) RegisterCommand(name string, fn CommandFunc) {
+	c.commands[name] = fn
+}
+
+// RegisterCvar adds a cvar available regardless of which scene is active,
+// for state that doesn't belong to any one scene (see los_debug/mon_think
+// in render.newConsole). Scene-scoped cvars from SetActiveScene take
+// precedence over these if the names collide.
+func (c *Console) RegisterCvar(cvar *Cvar) {
+	c.globalCvars[cvar.Name] = cvar
+}
+
+// lookupCvar finds name among the active scene's cvars first, falling
+// back to the globally registered ones.
+func (c *Console) lookupCvar(name string) (*Cvar, bool) {
+	if cvar, ok := c.cvars[name]; ok {
+		return cvar, true
+	}
+	cvar, ok := c.globalCvars[name]
+	return cvar, ok
+}
+
+// SetActiveScene points the console's cvars and spawn/clear commands at
+// scene - its Cvars() (if it implements CvarSource) replace the
+// previously active scene's entirely, so e.g. `set gravity.g 5` only
+// exists while GravityScene is active. Call this whenever the active
+// scene changes (render.Game does so from a SceneEnterEvent subscriber).
+func (c *Console) SetActiveScene(scene interface{}) {
+	c.activeScene = scene
+	if source, ok := scene.(CvarSource); ok {
+		c.cvars = source.Cvars()
+	} else {
+		c.cvars = make(map[string]*Cvar)
+	}
+}
+
+// Visible reports whether the overlay is currently shown.
+func (c *Console) Visible() bool {
+	return c.visible
+}
+
+// Update toggles visibility on backtick and, while visible, accumulates
+// typed input and dispatches a line on Enter. Call it once per tick from
+// Game.Update, ahead of the active scene's own input handling, so a
+// keystroke opening or typing into the console doesn't also reach the
+// scene underneath it.
+func (c *Console) Update() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyGraveAccent) {
+		c.visible = !c.visible
+		c.input = c.input[:0]
+		return
+	}
+	if !c.visible {
+		return
+	}
+
+	c.input = ebiten.AppendInputChars(c.input)
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(c.input) > 0 {
+		c.input = c.input[:len(c.input)-1]
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		line := strings.TrimSpace(string(c.input))
+		c.input = c.input[:0]
+		if line != "" {
+			c.appendLog("> " + line)
+			c.Dispatch(line)
+		}
+	}
+}
+
+// Dispatch parses and runs a single command line, logging its result.
+// Exported separately from Update so it can be driven by something other
+// than typed input later (e.g. a startup script of console commands).
+func (c *Console) Dispatch(line string) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return
+	}
+	name, args := fields[0], fields[1:]
+
+	switch name {
+	case "set":
+		c.dispatchSet(args)
+	case "get":
+		c.dispatchGet(args)
+	case "spawn":
+		c.dispatchSpawn(args)
+	case "clear":
+		c.dispatchClear()
+	default:
+		if fn, ok := c.commands[name]; ok {
+			if err := fn(args); err != nil {
+				c.appendLog(fmt.Sprintf("error: %v", err))
+			}
+			return
+		}
+		c.appendLog(fmt.Sprintf("unknown command: %s", name))
+	}
+}
+
+func (c *Console) dispatchSet(args []string) {
+	if len(args) != 2 {
+		c.appendLog("usage: set <cvar> <value>")
+		return
+	}
+	cvar, ok := c.lookupCvar(args[0])
+	if !ok {
+		c.appendLog(fmt.Sprintf("unknown cvar: %s", args[0]))
+		return
+	}
+	if err := cvar.Set(args[1]); err != nil {
+		c.appendLog(fmt.Sprintf("error: %v", err))
+		return
+	}
+	c.appendLog(fmt.Sprintf("%s = %s", args[0], cvar.Get()))
+}
+
+func (c *Console) dispatchGet(args []string) {
+	if len(args) != 1 {
+		c.appendLog("usage: get <cvar>")
+		return
+	}
+	cvar, ok := c.lookupCvar(args[0])
+	if !ok {
+		c.appendLog(fmt.Sprintf("unknown cvar: %s", args[0]))
+		return
+	}
+	c.appendLog(fmt.Sprintf("%s = %s", args[0], cvar.Get()))
+}
+
+func (c *Console) dispatchSpawn(args []string) {
+	n := 1
+	if len(args) > 0 {
+		parsed, err := strconv.Atoi(args[0])
+		if err != nil {
+			c.appendLog(fmt.Sprintf("invalid count: %q", args[0]))
+			return
+		}
+		n = parsed
+	}
+	spawner, ok := c.activeScene.(Spawner)
+	if !ok {
+		c.appendLog("active scene doesn't support spawn")
+		return
+	}
+	spawner.Spawn(n)
+	c.appendLog(fmt.Sprintf("spawned %d", n))
+}
+
+func (c *Console) dispatchClear() {
+	clearer, ok := c.activeScene.(Clearer)
+	if !ok {
+		c.appendLog("active scene doesn't support clear")
+		return
+	}
+	clearer.Clear()
+	c.appendLog("cleared")
+}
+
+func (c *Console) appendLog(line string) {
+	c.log = append(c.log, line)
+	if len(c.log) > maxLogLines {
+		c.log = c.log[len(c.log)-maxLogLines:]
+	}
+}
+
+// Lines returns the most recent n log lines (fewer if there aren't that
+// many yet), oldest first - for the overlay to draw.
+func (c *Console) Lines(n int) []string {
+	if len(c.log) <= n {
+		return c.log
+	}
+	return c.log[len(c.log)-n:]
+}
+
+// InputLine returns the current, not-yet-submitted input buffer text.
+func (c *Console) InputLine() string {
+	return string(c.input)
+}
@@ -0,0 +1,54 @@
+package events
+
+import "sync"
+
+// Handler receives events published on a Bus.
+type Handler func(Event)
+
+// Bus is a simple synchronous publish/subscribe registry. Publish calls
+// every subscribed Handler in turn, on the publishing goroutine - there's
+// no queueing or buffering, since every current publisher (Game.Update)
+// and subscriber (scenes, future audio/networking subsystems) already
+// run on the same game loop tick.
+type Bus struct {
+	mu       sync.Mutex
+	handlers map[int]Handler
+	nextID   int
+}
+
+// NewBus creates an empty Bus.
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[int]Handler)}
+}
+
+// Subscribe registers h to receive every future Publish call, returning a
+// function that removes it again.
+func (b *Bus) Subscribe(h Handler) (unsubscribe func()) {
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.handlers[id] = h
+	b.mu.Unlock()
+
+	return func() {
+		b.mu.Lock()
+		delete(b.handlers, id)
+		b.mu.Unlock()
+	}
+}
+
+// Publish calls every subscribed Handler with ev, in registration order.
+func (b *Bus) Publish(ev Event) {
+	b.mu.Lock()
+	handlers := make([]Handler, 0, len(b.handlers))
+	for id := 0; id < b.nextID; id++ {
+		if h, ok := b.handlers[id]; ok {
+			handlers = append(handlers, h)
+		}
+	}
+	b.mu.Unlock()
+
+	for _, h := range handlers {
+		h(ev)
+	}
+}
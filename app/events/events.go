@@ -0,0 +1,67 @@
+// Package events defines the discrete, edge-triggered events published on
+// a Bus (see bus.go) as an alternative to per-frame input polling. Render
+// code publishes these from Game.Update/Layout/StopGame; scenes and other
+// subsystems (audio, networking) subscribe to react to them without having
+// to poll ebiten themselves.
+package events
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// Event is any value published on a Bus. Subscribers type-switch on it to
+// find the events they care about.
+type Event interface{}
+
+// MouseMoveEvent fires when the cursor position changes since last frame.
+type MouseMoveEvent struct {
+	X, Y int
+}
+
+// MouseDownEvent fires the frame a mouse button transitions to pressed.
+type MouseDownEvent struct {
+	Button ebiten.MouseButton
+	X, Y   int
+}
+
+// MouseUpEvent fires the frame a mouse button transitions to released.
+type MouseUpEvent struct {
+	Button ebiten.MouseButton
+	X, Y   int
+}
+
+// KeyDownEvent fires the frame a key transitions to pressed.
+type KeyDownEvent struct {
+	Key ebiten.Key
+}
+
+// KeyUpEvent fires the frame a key transitions to released.
+type KeyUpEvent struct {
+	Key ebiten.Key
+}
+
+// WheelEvent fires whenever the scroll wheel moves, carrying the same
+// x/y deltas as ebiten.Wheel().
+type WheelEvent struct {
+	DX, DY float64
+}
+
+// SceneEnterEvent fires after SceneManager.Goto has loaded and entered a
+// scene, named by its registered display name.
+type SceneEnterEvent struct {
+	Scene string
+}
+
+// SceneExitEvent fires before SceneManager.Goto calls OnExit on the
+// outgoing scene, named by its registered display name.
+type SceneExitEvent struct {
+	Scene string
+}
+
+// WindowResizeEvent fires when Game.Layout observes the outside window
+// size change.
+type WindowResizeEvent struct {
+	Width, Height int
+}
+
+// ShutdownEvent fires once, right before Game begins tearing down (see
+// StopGame), so subscribers can flush state before the process exits.
+type ShutdownEvent struct{}
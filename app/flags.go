@@ -0,0 +1,30 @@
+//go:build !js || !wasm
+
+package main
+
+import (
+	"flag"
+
+	"app/render/scenes"
+)
+
+// parseStartupFlags registers the startup command-line flags and parses
+// them (together with any other flags registered elsewhere, e.g. -config),
+// returning the result as scenes.StartupFlags. Letting developers skip the
+// title screen and jump straight into a scene saves repeatedly navigating
+// the menu while iterating on it.
+func parseStartupFlags() *scenes.StartupFlags {
+	skipTitle := flag.Bool("skip-title", false, "Skip the title screen and jump straight to -scene")
+	scene := flag.String("scene", "", "Name of the scene to start in, used with -skip-title")
+	fullscreen := flag.Bool("fullscreen", false, "Start the window in fullscreen mode")
+	debugFPS := flag.Bool("debug-fps", false, "Overlay the current FPS/TPS on screen")
+	flag.Parse()
+
+	return &scenes.StartupFlags{
+		SkipTitle:   *skipTitle,
+		SceneName:   *scene,
+		Fullscreen:  *fullscreen,
+		DebugFPS:    *debugFPS,
+		EscapeExits: true,
+	}
+}
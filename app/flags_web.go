@@ -0,0 +1,25 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"flag"
+
+	"app/render/scenes"
+)
+
+// parseStartupFlags hardcodes sensible defaults for the web build: the
+// title screen is never skipped, the window can't go fullscreen, and
+// Escape shouldn't quit the page out from under the player. -config is
+// still registered elsewhere, so flag.Parse() is still needed here.
+func parseStartupFlags() *scenes.StartupFlags {
+	flag.Parse()
+
+	return &scenes.StartupFlags{
+		SkipTitle:   false,
+		SceneName:   "",
+		Fullscreen:  false,
+		DebugFPS:    false,
+		EscapeExits: false,
+	}
+}
@@ -14,7 +14,7 @@ import (
 func main() {
 	// Load configuration
 	configFile := flag.String("config", "", "Path to configuration YAML file")
-	flag.Parse()
+	startupFlags := parseStartupFlags()
 	if *configFile == "" {
 		log.Fatal("-config not given.")
 	}
@@ -22,6 +22,7 @@ func main() {
 	if err != nil {
 		log.Fatalf("Fail to load config file from %v. %v", *configFile, err)
 	}
+	config.WatchReloadOnSIGHUP(*configFile)
 
 	// Setup Logger
 	common.SetupLogger(config)
@@ -32,6 +33,6 @@ func main() {
 	comfyuiService.Start()
 
 	// Setup Render
-	game := render.NewGame(config, comfyuiService)
+	game := render.NewGame(config, comfyuiService, startupFlags)
 	render.RunGame(game)
 }
@@ -0,0 +1,130 @@
+package net
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+)
+
+// reconcileBlend is how much of the remaining gap between an entity's
+// last displayed position and its newest authoritative one Entities()
+// closes per call - the same easing idiom camera.Camera.Smoothing uses
+// for Target-follow, so an ordinary per-tick correction blends in
+// smoothly instead of popping every TickInterval (FixedUpdate calls
+// Entities far more often than that).
+const reconcileBlend = 0.3
+
+// reconcileSnapDistance is how far, in world units, an entity's
+// authoritative position may have moved since it was last displayed
+// before Entities() snaps to it outright instead of blending. Past this
+// distance the gap is a real catch-up (e.g. after Server.Broadcast
+// dropped a delta for a full send buffer and this client's next delta
+// covers everything it missed), not a normal per-tick correction, and
+// blending it would look like slow-motion teleporting instead of
+// smoothing anything out.
+const reconcileSnapDistance = 150
+
+// Client connects to a hosting Server and keeps a local snapshot of its
+// broadcast entity state, folding in each delta as it arrives in the
+// background and acking it so the server knows to diff this client's
+// next delta from here (see Server.Broadcast). Call Entities from the
+// joining scene's FixedUpdate to read the latest state - there's nothing
+// else to send back, the host is authoritative (see Server.readLoop).
+type Client struct {
+	conn *websocket.Conn
+
+	mu      sync.Mutex
+	snap    snapshot // last delta applied, authoritative as of seq
+	seq     uint32
+	display snapshot // what Entities() last reported, eased toward snap
+}
+
+// Dial connects to a Server's ServeHTTP endpoint (addr like
+// "ws://host:port/ws") and starts reading deltas in the background.
+func Dial(addr string) (*Client, error) {
+	conn, _, err := websocket.DefaultDialer.Dial(addr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("dialing online sandbox server %s: %w", addr, err)
+	}
+	c := &Client{conn: conn, snap: snapshot{}, display: snapshot{}}
+	go c.readLoop()
+	return c, nil
+}
+
+func (c *Client) readLoop() {
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			log.WithError(err).Info("Disconnected from online sandbox server")
+			return
+		}
+
+		d, err := decodeDelta(data)
+		if err != nil {
+			log.WithError(err).Debug("Discarding malformed online sandbox delta")
+			continue
+		}
+
+		c.mu.Lock()
+		c.snap = apply(c.snap, d)
+		c.seq = d.Seq
+		c.mu.Unlock()
+
+		ackData, err := encodeAck(ackMsg{Seq: d.Seq})
+		if err != nil {
+			log.WithError(err).Error("Failed to encode online sandbox ack")
+			continue
+		}
+		if err := c.conn.WriteMessage(websocket.BinaryMessage, ackData); err != nil {
+			log.WithError(err).Debug("Failed to ack online sandbox delta, dropping connection")
+			return
+		}
+	}
+}
+
+// Entities returns the most recently reconciled snapshot, with each
+// entity's position eased toward its latest authoritative value (see
+// reconcileBlend/reconcileSnapDistance) rather than popping to it the
+// instant a delta arrives.
+func (c *Client) Entities() []Entity {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	next := make(snapshot, len(c.snap))
+	for id, target := range c.snap {
+		disp, ok := c.display[id]
+		if !ok {
+			next[id] = target
+			continue
+		}
+		next[id] = blendEntity(disp, target)
+	}
+	c.display = next
+
+	return next.entities()
+}
+
+// blendEntity eases disp's position toward target's by reconcileBlend,
+// unless target has moved more than reconcileSnapDistance since disp was
+// last displayed, in which case it snaps straight to target - see
+// reconcileSnapDistance. Every other field comes from target unmodified.
+func blendEntity(disp, target Entity) Entity {
+	dx := target.X - disp.X
+	dy := target.Y - disp.Y
+	if math.Hypot(float64(dx), float64(dy)) > reconcileSnapDistance {
+		return target
+	}
+
+	out := target
+	out.X = disp.X + dx*reconcileBlend
+	out.Y = disp.Y + dy*reconcileBlend
+	return out
+}
+
+// Close disconnects from the server.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
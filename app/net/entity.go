@@ -0,0 +1,86 @@
+// Package net implements the online sandbox: a minimal websocket host/join
+// layer that lets one scene's live simulation state be shared between a
+// hosting process and any number of joining ones, broadcast as per-tick
+// delta snapshots. It's the server-side counterpart to
+// services.ComfyUIService's client-only websocket usage - see Server for
+// the hosting half and Client for the joining half.
+//
+// Each client acks the Seq of the last delta it applied (see Client's
+// readLoop and Server.readLoop), so Server.Broadcast can diff every
+// client against its own last-acked snapshot instead of one baseline
+// shared by all of them, and a delta dropped for one slow client (full
+// send buffer) just grows that client's next diff instead of leaving it
+// permanently desynced. Scope is otherwise still narrow: the host's
+// simulation is authoritative and clients never send state back beyond
+// that ack, so this is a shared-spectator sandbox, not a lockstep or
+// client-authoritative netcode model.
+package net
+
+import "time"
+
+// TickInterval is how often the hosting scene should call Server.Broadcast
+// - fast enough to feel live, slow enough not to saturate a LAN websocket
+// with a delta every physics substep.
+const TickInterval = 50 * time.Millisecond
+
+// Entity is one simulated object's networked state: just enough for a
+// remote peer to draw and reconcile it, not a scene's full internal
+// representation. ID must be stable across a given Source's ticks, since
+// Server/Client diff snapshots by it - see BallsScene.Entities for how
+// that's satisfied today. Every field is fixed-size and in wire order
+// (see wire.go), so don't reorder or widen one without checking encode/
+// decodeDelta still agree with each other.
+type Entity struct {
+	ID     uint32
+	X      float32
+	Y      float32
+	VX     float32
+	VY     float32
+	Radius float32
+	R      uint8
+	G      uint8
+	B      uint8
+}
+
+// Source is implemented by scenes that can participate in the online
+// sandbox (see BallsScene.Entities/ApplySnapshot). Entities reports the
+// current simulation state for Server to diff and broadcast; ApplySnapshot
+// replaces a joining Client's local state with whatever the host most
+// recently sent.
+type Source interface {
+	Entities() []Entity
+	ApplySnapshot(entities []Entity)
+}
+
+// Session holds whichever one of Server/Client this process's online
+// sandbox is currently using - at most one is ever non-nil. A nil
+// *Session (the default, see Deps.Net) means no networking is active, and
+// every method below is nil-safe so callers don't need to check for that
+// separately - the same pattern Deps.Bus uses for SceneManager.publish.
+type Session struct {
+	Server *Server
+	Client *Client
+}
+
+// Hosting reports whether this Session is broadcasting as a Server.
+func (s *Session) Hosting() bool {
+	return s != nil && s.Server != nil
+}
+
+// Joined reports whether this Session is connected as a Client.
+func (s *Session) Joined() bool {
+	return s != nil && s.Client != nil
+}
+
+// Close disconnects the Client side of the session, if any. It's a no-op
+// on a nil Session, a hosting one, or one with neither side set. The
+// Server side has no connection of its own to close - it's the caller's
+// http.Server listener that owns that, see OnlineScene.disconnect.
+func (s *Session) Close() {
+	if s == nil {
+		return
+	}
+	if s.Client != nil {
+		s.Client.Close()
+	}
+}
@@ -0,0 +1,194 @@
+package net
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+)
+
+// serverClientSendBuffer bounds how many un-flushed deltas a client's
+// writeLoop will queue before Broadcast starts dropping them for it,
+// rather than letting one slow connection back up the host's tick loop.
+// A dropped delta isn't lost - that client's next diff is just computed
+// against an older baseline (see Broadcast/history) and comes out bigger.
+const serverClientSendBuffer = 8
+
+// Server hosts the online sandbox: it upgrades incoming HTTP requests to
+// websocket connections and, once per Broadcast call, diffs a Source's
+// current state against each client's own last-acked snapshot - not one
+// baseline shared by every client - sending each client only what's
+// changed since wherever it individually last caught up to. Mount it at
+// whatever path joining Clients dial, e.g. http.Handle("/ws", server);
+// http.ListenAndServe(addr, nil).
+type Server struct {
+	upgrader websocket.Upgrader
+
+	mu      sync.Mutex
+	clients map[*serverClient]struct{}
+	seq     uint32
+	latest  snapshot
+
+	// history retains the cur snapshot passed to past Broadcast calls,
+	// keyed by the Seq it was sent under, so a client's next delta can be
+	// diffed from wherever it last acked instead of always from the most
+	// recent tick. Pruned every Broadcast down to whatever the slowest
+	// connected client still needs - see pruneHistory.
+	history map[uint32]snapshot
+}
+
+type serverClient struct {
+	conn *websocket.Conn
+	send chan delta
+
+	// acked is the highest delta Seq this client has confirmed applying
+	// (see Server.readLoop) - the key Broadcast looks up in history to
+	// diff this client's next delta from. 0 means nothing acked yet,
+	// which diffs against the implicit empty snapshot (history has no
+	// entry for Seq 0) - see Broadcast.
+	acked uint32
+}
+
+// NewServer creates a Server with no clients connected yet.
+func NewServer() *Server {
+	return &Server{
+		upgrader: websocket.Upgrader{
+			// The online sandbox is a LAN/demo feature with no origin
+			// policy to enforce, unlike services.ComfyUIService's
+			// outbound connection to a known, trusted host.
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+		clients: make(map[*serverClient]struct{}),
+		latest:  snapshot{},
+		history: make(map[uint32]snapshot),
+	}
+}
+
+// ServeHTTP upgrades the request to a websocket connection, sends it a
+// full snapshot of the host's current state, and registers it to receive
+// future Broadcast deltas until the connection closes.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.WithError(err).Warn("Failed to upgrade websocket connection")
+		return
+	}
+
+	client := &serverClient{conn: conn, send: make(chan delta, serverClientSendBuffer)}
+
+	s.mu.Lock()
+	full := delta{Seq: s.seq, Entities: s.latest.entities()}
+	client.acked = s.seq
+	s.clients[client] = struct{}{}
+	s.mu.Unlock()
+
+	client.send <- full
+
+	go s.writeLoop(client)
+	s.readLoop(client)
+}
+
+func (s *Server) writeLoop(c *serverClient) {
+	for d := range c.send {
+		data, err := d.encode()
+		if err != nil {
+			log.WithError(err).Error("Failed to encode online sandbox delta")
+			continue
+		}
+		if err := c.conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
+			log.WithError(err).Debug("Failed to write to online sandbox client, dropping connection")
+			c.conn.Close()
+			return
+		}
+	}
+}
+
+// readLoop applies every ack the client sends - the host's Source is
+// still authoritative, see the package doc, an ack is the only thing a
+// client ever sends back - and exists just as much to notice the
+// connection closing, since gorilla/websocket needs a reader for that.
+func (s *Server) readLoop(c *serverClient) {
+	defer s.removeClient(c)
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		a, err := decodeAck(data)
+		if err != nil {
+			log.WithError(err).Debug("Discarding malformed ack from online sandbox client")
+			continue
+		}
+
+		s.mu.Lock()
+		if a.Seq > c.acked {
+			c.acked = a.Seq
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *Server) removeClient(c *serverClient) {
+	s.mu.Lock()
+	delete(s.clients, c)
+	s.mu.Unlock()
+	close(c.send)
+}
+
+// Broadcast diffs source's current entities against each connected
+// client's own last-acked snapshot and sends the result to that client.
+// Call it once per tick (see TickInterval) from the hosting scene's
+// FixedUpdate.
+func (s *Server) Broadcast(source Source) {
+	cur := toSnapshot(source.Entities())
+
+	s.mu.Lock()
+	s.seq++
+	seq := s.seq
+	s.history[seq] = cur
+	s.latest = cur
+
+	type pendingSend struct {
+		client *serverClient
+		d      delta
+	}
+	sends := make([]pendingSend, 0, len(s.clients))
+	for c := range s.clients {
+		d := diff(s.history[c.acked], cur)
+		if len(d.Entities) == 0 && len(d.Removed) == 0 {
+			continue
+		}
+		d.Seq = seq
+		sends = append(sends, pendingSend{c, d})
+	}
+	s.pruneHistory()
+	s.mu.Unlock()
+
+	for _, p := range sends {
+		select {
+		case p.client.send <- p.d:
+		default:
+			log.WithField("client_acked_seq", p.client.acked).Warn(
+				"Dropping online sandbox delta, send buffer full - client will resync once it catches up")
+		}
+	}
+}
+
+// pruneHistory drops snapshots older than every connected client's acked
+// Seq, since Broadcast will never need to diff against them again. Must
+// be called with mu held.
+func (s *Server) pruneHistory() {
+	min := s.seq
+	for c := range s.clients {
+		if c.acked < min {
+			min = c.acked
+		}
+	}
+	for seq := range s.history {
+		if seq < min {
+			delete(s.history, seq)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package net
+
+// snapshot is one tick's full entity list, keyed by ID, so it can be
+// diffed against the previous tick's to produce a delta.
+type snapshot map[uint32]Entity
+
+func toSnapshot(entities []Entity) snapshot {
+	s := make(snapshot, len(entities))
+	for _, e := range entities {
+		s[e.ID] = e
+	}
+	return s
+}
+
+func (s snapshot) entities() []Entity {
+	out := make([]Entity, 0, len(s))
+	for _, e := range s {
+		out = append(out, e)
+	}
+	return out
+}
+
+// delta is the wire message Server broadcasts each tick: Seq is the
+// monotonically increasing tick it was diffed against (see
+// Server.Broadcast), Entities holds every entity that's new or has
+// changed since whatever snapshot the recipient last acked, and Removed
+// holds the IDs of entities that vanished. A just-connected client is
+// sent a delta diffed against an empty snapshot, i.e. every entity it
+// needs to build its first full picture. See wire.go for how this is
+// serialized on the connection.
+type delta struct {
+	Seq      uint32
+	Entities []Entity
+	Removed  []uint32
+}
+
+// diff computes the delta that takes a peer holding prev to cur.
+func diff(prev, cur snapshot) delta {
+	var d delta
+	for id, e := range cur {
+		if old, ok := prev[id]; !ok || old != e {
+			d.Entities = append(d.Entities, e)
+		}
+	}
+	for id := range prev {
+		if _, ok := cur[id]; !ok {
+			d.Removed = append(d.Removed, id)
+		}
+	}
+	return d
+}
+
+// apply folds d into prev, returning the resulting snapshot. prev is left
+// untouched.
+func apply(prev snapshot, d delta) snapshot {
+	next := make(snapshot, len(prev)+len(d.Entities))
+	for id, e := range prev {
+		next[id] = e
+	}
+	for _, e := range d.Entities {
+		next[e.ID] = e
+	}
+	for _, id := range d.Removed {
+		delete(next, id)
+	}
+	return next
+}
@@ -0,0 +1,95 @@
+package net
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// ackMsg is what Client sends back to Server after applying a delta, so
+// Server.Broadcast can diff each client against its own last-acked
+// snapshot instead of the single shared baseline every client used to
+// share (see Server.history).
+type ackMsg struct {
+	Seq uint32
+}
+
+// delta and ackMsg are encoded as fixed-width little-endian binary rather
+// than JSON: this sandbox sends one of these every TickInterval to every
+// connected client, and Entity is already just fixed-size numeric fields,
+// so there's no reason to pay JSON's field-name/quoting overhead on a
+// message this hot.
+
+func (d delta) encode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, d.Seq); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(&buf, binary.LittleEndian, uint32(len(d.Entities))); err != nil {
+		return nil, err
+	}
+	for _, e := range d.Entities {
+		if err := binary.Write(&buf, binary.LittleEndian, e); err != nil {
+			return nil, err
+		}
+	}
+	if err := binary.Write(&buf, binary.LittleEndian, uint32(len(d.Removed))); err != nil {
+		return nil, err
+	}
+	for _, id := range d.Removed {
+		if err := binary.Write(&buf, binary.LittleEndian, id); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeDelta(data []byte) (delta, error) {
+	r := bytes.NewReader(data)
+
+	var d delta
+	if err := binary.Read(r, binary.LittleEndian, &d.Seq); err != nil {
+		return delta{}, fmt.Errorf("decoding delta seq: %w", err)
+	}
+
+	var entityCount uint32
+	if err := binary.Read(r, binary.LittleEndian, &entityCount); err != nil {
+		return delta{}, fmt.Errorf("decoding delta entity count: %w", err)
+	}
+	d.Entities = make([]Entity, entityCount)
+	for i := range d.Entities {
+		if err := binary.Read(r, binary.LittleEndian, &d.Entities[i]); err != nil {
+			return delta{}, fmt.Errorf("decoding delta entity %d: %w", i, err)
+		}
+	}
+
+	var removedCount uint32
+	if err := binary.Read(r, binary.LittleEndian, &removedCount); err != nil {
+		return delta{}, fmt.Errorf("decoding delta removed count: %w", err)
+	}
+	d.Removed = make([]uint32, removedCount)
+	for i := range d.Removed {
+		if err := binary.Read(r, binary.LittleEndian, &d.Removed[i]); err != nil {
+			return delta{}, fmt.Errorf("decoding delta removed id %d: %w", i, err)
+		}
+	}
+
+	return d, nil
+}
+
+func encodeAck(a ackMsg) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, a.Seq); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeAck(data []byte) (ackMsg, error) {
+	r := bytes.NewReader(data)
+	var a ackMsg
+	if err := binary.Read(r, binary.LittleEndian, &a.Seq); err != nil {
+		return ackMsg{}, fmt.Errorf("decoding ack: %w", err)
+	}
+	return a, nil
+}
@@ -0,0 +1,44 @@
+// Package physics provides deterministic, fixed-timestep simulation
+// primitives shared by the physics demo scenes (BallsScene, GravityScene):
+// a frame-rate-independent Accumulator, a dense SpatialHash broadphase for
+// uniform-size contact solving, and a Barnes-Hut Quadtree broadphase for
+// N-body forces like gravity.
+package physics
+
+import "time"
+
+// Timestep is the fixed simulation tick (~60Hz), chosen so collision and
+// gravity integration behave identically regardless of the host's actual
+// display refresh rate or a momentary frame hitch.
+const Timestep = 16667 * time.Microsecond
+
+// maxStepsPerFrame caps how many fixed steps Accumulator.Advance will ever
+// return for a single frame, so a long stall (the window being dragged,
+// a debugger breakpoint) can't force minutes of catch-up simulation - the
+// game just visibly slows down instead of freezing to "catch up".
+const maxStepsPerFrame = 5
+
+// Accumulator converts variable-length frame deltas into a whole number of
+// Timestep-sized physics steps, carrying any leftover time into the next
+// frame so the simulation rate doesn't depend on how often Advance is
+// called.
+type Accumulator struct {
+	remainder time.Duration
+}
+
+// Advance adds frameDelta to the accumulator and returns how many fixed
+// steps the caller should run this frame, each of length Timestep.
+func (a *Accumulator) Advance(frameDelta time.Duration) int {
+	a.remainder += frameDelta
+	steps := 0
+	for a.remainder >= Timestep && steps < maxStepsPerFrame {
+		a.remainder -= Timestep
+		steps++
+	}
+	if a.remainder > Timestep*maxStepsPerFrame {
+		// Fell too far behind (e.g. the process was paused) - drop the
+		// backlog rather than ever trying to catch up.
+		a.remainder = 0
+	}
+	return steps
+}
@@ -0,0 +1,197 @@
+package physics
+
+import "math"
+
+// Theta is the usual default Barnes-Hut accuracy/speed trade-off: a node
+// is treated as a single aggregate body once its size divided by its
+// distance from the query point falls below Theta. Lower is more
+// accurate (closer to brute-force O(N^2)); 0 would disable the
+// approximation entirely.
+const Theta = 0.5
+
+// Body is a point mass a Quadtree can aggregate for Barnes-Hut force
+// approximation. ID identifies the body to the caller (its index into
+// whatever slice it came from) so ForceOn can recognize and skip a body
+// querying against itself.
+type Body struct {
+	ID   int
+	X, Y float64
+	Mass float64
+}
+
+// maxQuadtreeDepth bounds how many times Insert will keep splitting a
+// node that still holds more than one body. Two bodies at (or extremely
+// close to) the exact same coordinates bisect into the same quadrant
+// every time, so without a limit split() recurses until the stack
+// overflows - GravityScene can produce this whenever its spawner places
+// two bodies at the same pixel (e.g. pressing C twice without moving the
+// mouse). Past this depth, a node just buckets every further body
+// directly (see Body.bodies) instead of splitting again.
+const maxQuadtreeDepth = 32
+
+// Quadtree is a Barnes-Hut tree over a fixed world AABB: it recursively
+// subdivides into four quadrants until every leaf holds at most one body
+// (or maxQuadtreeDepth stops it from splitting further - see bodies), and
+// every node (leaf or internal) stores the total mass and center of mass
+// of everything beneath it. ForceOn uses that to approximate a distant
+// cluster of bodies as a single point instead of visiting each of them,
+// bringing an N-body force sum from O(N^2) to O(N log N).
+type Quadtree struct {
+	minX, minY, maxX, maxY float64
+	depth                  int
+
+	bodyCount int  // 0 (empty), 1 (leaf), or >1 (split, or a capped leaf - see bodies)
+	body      Body // the one body, if bodyCount == 1
+
+	// bodies holds every body beyond the first once this node hit
+	// maxQuadtreeDepth and stopped splitting - nil for a split node
+	// (children != nil) or a leaf still holding just body.
+	bodies []Body
+
+	mass       float64 // aggregate mass of every body beneath this node
+	comX, comY float64 // aggregate center of mass
+
+	children [4]*Quadtree // nil until this node splits
+}
+
+// NewQuadtree creates an empty tree over the given world bounds. Bodies
+// Inserted outside these bounds will still be placed (in whichever
+// quadrant their coordinate falls on), just without the balanced
+// subdivision the tree relies on for its O(log N) depth.
+func NewQuadtree(minX, minY, maxX, maxY float64) *Quadtree {
+	return &Quadtree{minX: minX, minY: minY, maxX: maxX, maxY: maxY}
+}
+
+// Insert adds b to the tree, splitting nodes as needed so every leaf ends
+// up holding at most one body. Bodies with zero or negative mass are
+// ignored, since they contribute nothing to any aggregate.
+func (q *Quadtree) Insert(b Body) {
+	if b.Mass <= 0 {
+		return
+	}
+
+	totalMass := q.mass + b.Mass
+	q.comX = (q.comX*q.mass + b.X*b.Mass) / totalMass
+	q.comY = (q.comY*q.mass + b.Y*b.Mass) / totalMass
+	q.mass = totalMass
+
+	switch {
+	case q.bodyCount == 0:
+		q.body = b
+		q.bodyCount = 1
+
+	case q.children[0] != nil:
+		q.bodyCount++
+		q.insertIntoChild(b)
+
+	case q.bodyCount == 1 && q.depth < maxQuadtreeDepth:
+		existing := q.body
+		q.split()
+		q.bodyCount = 2
+		q.insertIntoChild(existing)
+		q.insertIntoChild(b)
+
+	default:
+		// Either a single body already at maxQuadtreeDepth, or an
+		// existing capped bucket below it - stop splitting and just
+		// bucket b here instead of recursing forever.
+		q.bodies = append(q.bodies, b)
+		q.bodyCount++
+	}
+}
+
+// leafBodies returns every body stored directly in this node when it's a
+// leaf (bodyCount == 1) or a capped bucket that stopped splitting at
+// maxQuadtreeDepth (bodyCount > 1, children nil) - q.body plus whatever
+// maxQuadtreeDepth forced into q.bodies.
+func (q *Quadtree) leafBodies() []Body {
+	if q.bodyCount == 0 {
+		return nil
+	}
+	return append([]Body{q.body}, q.bodies...)
+}
+
+func (q *Quadtree) split() {
+	midX := (q.minX + q.maxX) / 2
+	midY := (q.minY + q.maxY) / 2
+	q.children[0] = NewQuadtree(q.minX, q.minY, midX, midY) // top-left
+	q.children[1] = NewQuadtree(midX, q.minY, q.maxX, midY) // top-right
+	q.children[2] = NewQuadtree(q.minX, midY, midX, q.maxY) // bottom-left
+	q.children[3] = NewQuadtree(midX, midY, q.maxX, q.maxY) // bottom-right
+	for _, child := range q.children {
+		child.depth = q.depth + 1
+	}
+}
+
+func (q *Quadtree) insertIntoChild(b Body) {
+	midX := (q.minX + q.maxX) / 2
+	midY := (q.minY + q.maxY) / 2
+	index := 0
+	if b.X >= midX {
+		index |= 1
+	}
+	if b.Y >= midY {
+		index |= 2
+	}
+	q.children[index].Insert(b)
+}
+
+// ForceOn returns the acceleration (ax, ay) this tree's bodies exert via
+// gravity on a unit mass at (x, y), using g as the gravitational constant
+// (matching the caller's F = g*m1*m2/r^2 force law) and theta to decide
+// when a node's aggregate center of mass stands in for visiting its
+// individual bodies. selfID excludes the body with that ID from the sum,
+// so a body already inserted into the tree can safely query against it
+// without attracting itself.
+func (q *Quadtree) ForceOn(selfID int, x, y, g, theta float64) (ax, ay float64) {
+	if q == nil || q.mass <= 0 {
+		return 0, 0
+	}
+	if q.bodyCount == 1 && q.body.ID == selfID {
+		return 0, 0
+	}
+
+	if q.bodyCount > 1 && q.children[0] == nil {
+		// A capped bucket that hit maxQuadtreeDepth instead of splitting
+		// further - sum its (few) bodies individually rather than
+		// treating them as one aggregate point, which would wrongly
+		// include selfID's own mass if it's one of them.
+		for _, body := range q.leafBodies() {
+			if body.ID == selfID {
+				continue
+			}
+			dx := body.X - x
+			dy := body.Y - y
+			distSq := dx*dx + dy*dy
+			if distSq < 1 {
+				distSq = 1
+			}
+			dist := math.Sqrt(distSq)
+			accel := g * body.Mass / distSq
+			ax += accel * dx / dist
+			ay += accel * dy / dist
+		}
+		return ax, ay
+	}
+
+	dx := q.comX - x
+	dy := q.comY - y
+	distSq := dx*dx + dy*dy
+	if distSq < 1 {
+		distSq = 1
+	}
+
+	size := q.maxX - q.minX
+	if q.bodyCount <= 1 || size*size < theta*theta*distSq {
+		dist := math.Sqrt(distSq)
+		accel := g * q.mass / distSq
+		return accel * dx / dist, accel * dy / dist
+	}
+
+	for _, child := range q.children {
+		cax, cay := child.ForceOn(selfID, x, y, g, theta)
+		ax += cax
+		ay += cay
+	}
+	return ax, ay
+}
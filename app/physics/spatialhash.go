@@ -0,0 +1,81 @@
+package physics
+
+import "math"
+
+// SpatialHash buckets 2D points into a uniform grid of CellSize, for
+// broadphase collision checks over a dense, frequently-changing set of
+// circles. Buckets are reused frame-to-frame via Reset (which truncates
+// their length without freeing the backing array) instead of the caller
+// allocating a fresh map every tick, so a steady stream of spawns doesn't
+// thrash the garbage collector.
+type SpatialHash struct {
+	cellSize float64
+	buckets  map[int64][]int
+	touched  []int64
+}
+
+// NewSpatialHash creates a hash with the given cell size, in the same
+// units as the points later passed to Cell/Insert.
+func NewSpatialHash(cellSize float64) *SpatialHash {
+	return &SpatialHash{
+		cellSize: cellSize,
+		buckets:  make(map[int64][]int),
+	}
+}
+
+// CellSize returns the grid cell size this hash was created with.
+func (h *SpatialHash) CellSize() float64 {
+	return h.cellSize
+}
+
+// Cell returns the grid coordinates containing world point (x, y).
+func (h *SpatialHash) Cell(x, y float64) (gx, gy int) {
+	return int(math.Floor(x / h.cellSize)), int(math.Floor(y / h.cellSize))
+}
+
+// Reset truncates every bucket touched since the last Reset back to
+// length 0, keeping its backing array so the next round of Insert calls
+// doesn't reallocate.
+func (h *SpatialHash) Reset() {
+	for _, key := range h.touched {
+		h.buckets[key] = h.buckets[key][:0]
+	}
+	h.touched = h.touched[:0]
+}
+
+// Insert adds index to the bucket for grid cell (gx, gy).
+func (h *SpatialHash) Insert(gx, gy, index int) {
+	key := cellKey(gx, gy)
+	bucket := h.buckets[key]
+	if len(bucket) == 0 {
+		// First write into this cell this frame - either it's never been
+		// allocated, or Reset truncated it back to 0 last frame. Either
+		// way it needs to be (re-)added to touched for ForEachBucket to
+		// see it; checking map presence instead would miss the reused
+		// case, since the key (and its now-empty backing array) is still
+		// present from before Reset.
+		h.touched = append(h.touched, key)
+	}
+	h.buckets[key] = append(bucket, index)
+}
+
+// Bucket returns the indices previously Inserted at grid cell (gx, gy).
+func (h *SpatialHash) Bucket(gx, gy int) []int {
+	return h.buckets[cellKey(gx, gy)]
+}
+
+// ForEachBucket calls fn once per non-empty bucket touched since the last
+// Reset, so callers can run a broadphase pass without knowing the grid's
+// extent up front.
+func (h *SpatialHash) ForEachBucket(fn func(indices []int)) {
+	for _, key := range h.touched {
+		fn(h.buckets[key])
+	}
+}
+
+// cellKey packs a cell coordinate into a single map key. Coordinates are
+// expected to fit in 16 bits (+/-32767 cells), which at any sane cell size
+// covers far more world space than these demo scenes ever use.
+func cellKey(gx, gy int) int64 {
+	return (int64(gx) << 16) | int64(gy&0xFFFF)
+}
@@ -0,0 +1,122 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"app/console"
+	"app/events"
+	"app/render/scenes"
+)
+
+// consoleVisibleLines caps how many past log lines the overlay shows at
+// once, independent of how many Console itself retains (see
+// console.maxLogLines).
+const consoleVisibleLines = 12
+
+// newConsole builds the dev console and registers the host-level commands
+// that aren't owned by any one scene (see console.Spawner/Clearer for the
+// scene-scoped spawn/clear). Commands here read/write g directly, so
+// they're wired up once NewGame's Game value exists rather than inside
+// Console itself.
+func newConsole(g *Game) *console.Console {
+	c := console.New()
+
+	c.RegisterCommand("scene", func(args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("usage: scene <name>")
+		}
+		id, ok := g.sceneManager.LookupByName(args[0])
+		if !ok {
+			return fmt.Errorf("unknown scene: %s", args[0])
+		}
+		g.sceneManager.Goto(id)
+		return nil
+	})
+
+	c.RegisterCommand("screenshot", func(args []string) error {
+		g.screenshotRequested = true
+		return nil
+	})
+
+	c.RegisterCommand("fps", func(args []string) error {
+		g.startup.DebugFPS = !g.startup.DebugFPS
+		return nil
+	})
+
+	// los_debug/mon_think are reserved for a future line-of-sight and
+	// monster-AI system; neither exists yet, so these are currently
+	// inert state the console can still toggle and read back, the same
+	// way common.Config.Theme.Font.Family is reserved ahead of its
+	// consumer.
+	c.RegisterCvar(console.NewBoolCvar("los_debug", &g.losDebug))
+	c.RegisterCvar(console.NewBoolCvar("mon_think", &g.monThink))
+
+	return c
+}
+
+// subscribeConsoleToScenes keeps console's active cvars/spawn/clear
+// target in sync with whichever scene is actually on screen, by watching
+// the same SceneEnterEvent SceneManager already publishes for audio (see
+// NewGame).
+func subscribeConsoleToScenes(bus *events.Bus, sceneManager *scenes.SceneManager, c *console.Console) {
+	bus.Subscribe(func(ev events.Event) {
+		enter, ok := ev.(events.SceneEnterEvent)
+		if !ok {
+			return
+		}
+		id, ok := sceneManager.LookupByName(enter.Scene)
+		if !ok {
+			return
+		}
+		if scene, ok := sceneManager.Get(id); ok {
+			c.SetActiveScene(scene)
+		}
+	})
+}
+
+// drawConsoleOverlay draws a translucent scrollback + input line above
+// whatever the active scene already drew, so the console reads like a
+// classic id-Tech-style developer console.
+func drawConsoleOverlay(screen *ebiten.Image, c *console.Console) {
+	bounds := screen.Bounds()
+	width := float32(bounds.Dx())
+	height := float32(16 * (consoleVisibleLines + 2))
+
+	vector.DrawFilledRect(screen, 0, 0, width, height, color.RGBA{0, 0, 0, 200}, false)
+
+	y := 4
+	for _, line := range c.Lines(consoleVisibleLines) {
+		ebitenutil.DebugPrintAt(screen, line, 4, y)
+		y += 16
+	}
+	ebitenutil.DebugPrintAt(screen, "> "+c.InputLine()+"_", 4, y+4)
+}
+
+// takeScreenshot reads the just-drawn frame back from screen and saves it
+// as a timestamped PNG in the working directory.
+func takeScreenshot(screen *ebiten.Image) error {
+	bounds := screen.Bounds()
+	img := image.NewRGBA(bounds)
+	screen.ReadPixels(img.Pix)
+
+	name := fmt.Sprintf("screenshot_%d.png", time.Now().UnixNano())
+	f, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("creating screenshot file: %w", err)
+	}
+	defer f.Close()
+
+	if err := png.Encode(f, img); err != nil {
+		return fmt.Errorf("encoding screenshot: %w", err)
+	}
+	return nil
+}
@@ -2,14 +2,22 @@ package render
 
 import (
 	"app/common"
+	"app/console"
+	"app/events"
+	"app/physics"
+	"app/services"
 
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 
 	"app/render/scenes"
 )
@@ -18,18 +26,42 @@ import (
 
 const (
 	WINDOW_TITLE = "Infinite Dungeon"
+
+	// minFrameInterval bounds how often FrameScheduler will let a redraw
+	// through, so a burst of input (holding a key, dragging the mouse)
+	// can't schedule more frames than the display can show.
+	minFrameInterval = time.Second / 144
 )
 
 type Game struct {
 	Config        *common.Config
 	Width, Height int
 
-	availableScenes map[scenes.SceneId]scenes.Scene
-	activeSceneId   scenes.SceneId
-	shutdown        bool
+	sceneManager *scenes.SceneManager
+	startup      *scenes.StartupFlags
+	shutdown     bool
+
+	frames      *scenes.FrameScheduler
+	bus         *events.Bus
+	inputDirty  bool
+	lastCursorX int
+	lastCursorY int
+
+	// physicsAccum and lastTick drive scenes.FixedUpdater at a fixed
+	// timestep independent of Ebiten's variable per-frame call rate; see
+	// app/physics.Accumulator.
+	physicsAccum physics.Accumulator
+	lastTick     time.Time
+
+	// console is the backtick-toggled dev console (see app/console and
+	// newConsole/drawConsoleOverlay in console.go). While visible, it
+	// takes over input entirely - no scene Update/FixedUpdate runs.
+	console             *console.Console
+	screenshotRequested bool
+	losDebug, monThink  bool // reserved cvars, see newConsole
 }
 
-func NewGame(config *common.Config) *Game {
+func NewGame(config *common.Config, comfyui *services.ComfyUIService, startup *scenes.StartupFlags) *Game {
 	log.Info("Initializing new game instance")
 
 	w := config.Render.Window.Width
@@ -40,46 +72,90 @@ func NewGame(config *common.Config) *Game {
 		"fullscreen": config.Render.Window.Fullscreen,
 	}).Info("Setting up game window configuration")
 
+	frames := scenes.NewFrameScheduler(minFrameInterval)
+	bus := events.NewBus()
+
 	// Initialize shared dependencies
 	deps := &scenes.Deps{
-		Config: config,
+		Config:  config,
+		Assets:  scenes.NewAssetStore(),
+		Audio:   scenes.NewDefaultAudioRegistry(config),
+		ComfyUI: comfyui,
+		Startup: startup,
+		Frames:  frames,
+		Bus:     bus,
 	}
 	log.Debug("Initialized scene dependencies")
 
-	// Populate deps.Scenes with the game scenes
-	availableScenes := map[scenes.SceneId]scenes.Scene{
-		scenes.StartSceneId:    scenes.NewStartScene(deps),
-		scenes.BallsSceneId:    scenes.NewBallsScene(deps),
-		scenes.GravitySceneId:  scenes.NewGravityScene(deps),
-		scenes.ComfyUISceneId:  scenes.NewComfyUIScene(deps),
-		scenes.GameOverSceneId: scenes.NewGameOverScene(deps),
+	// Loop menu music whenever the title screen becomes active, and stop
+	// it for everything else - a direct consumer of the scene lifecycle
+	// events SceneManager.Goto publishes on Bus.
+	bus.Subscribe(func(ev events.Event) {
+		enter, ok := ev.(events.SceneEnterEvent)
+		if !ok {
+			return
+		}
+		if enter.Scene == scenes.StartSceneName {
+			deps.Audio.Loop("menu_music")
+		} else {
+			deps.Audio.Stop("menu_music")
+		}
+	})
+
+	// The scene registry (populated by each scene's init()) is wrapped by
+	// a SceneManager that lazily instantiates scenes as they're visited.
+	sceneManager := scenes.NewSceneManager(deps)
+	deps.SceneManager = sceneManager
+
+	initialSceneId := scenes.StartSceneId
+	if startup.SkipTitle && startup.SceneName != "" {
+		if id, ok := sceneManager.LookupByName(startup.SceneName); ok {
+			initialSceneId = id
+			log.WithField("scene_name", startup.SceneName).Info("Skipping title screen via -skip-title/-scene")
+		} else {
+			log.WithField("scene_name", startup.SceneName).Warn("Unknown -scene name, falling back to title screen")
+		}
 	}
 
-	activeSceneId := scenes.StartSceneId
-	log.WithField("initial_scene", activeSceneId).Info("Setting initial active scene")
-
-	availableScenes[activeSceneId].FirstLoad()
-	log.WithField("scene_id", activeSceneId).Debug("Initial scene loaded")
-
-	log.Info("Game initialization complete")
-	return &Game{
-		Config:          config,
-		availableScenes: availableScenes,
-		activeSceneId:   activeSceneId,
-		shutdown:        false,
+	g := &Game{
+		Config:       config,
+		sceneManager: sceneManager,
+		startup:      startup,
+		shutdown:     false,
+		frames:       frames,
+		bus:          bus,
+		inputDirty:   true, // force the first frame to render
+		lastTick:     time.Now(),
 
 		Width:  w,
 		Height: h,
 	}
+
+	// Registered before Goto below, like the menu-music subscription
+	// above, so the console's active-scene cvars are already pointed at
+	// the initial scene rather than waiting for the first transition.
+	g.console = newConsole(g)
+	subscribeConsoleToScenes(bus, sceneManager, g.console)
+
+	log.WithField("initial_scene", initialSceneId).Info("Setting initial active scene")
+	sceneManager.Goto(initialSceneId)
+
+	log.Info("Game initialization complete")
+	return g
 }
 
 func StopGame(g *Game) error {
-	log.WithField("active_scene", g.activeSceneId).Info("Shutting down Game")
+	log.WithField("active_scene", g.sceneManager.Current()).Info("Shutting down Game")
+
+	g.bus.Publish(events.ShutdownEvent{})
 
 	// Stop current scene
-	activeScene := g.availableScenes[g.activeSceneId]
-	log.WithField("scene_id", g.activeSceneId).Debug("Calling OnExit for active scene")
-	activeScene.OnExit()
+	if activeScene, ok := g.sceneManager.Get(g.sceneManager.Current()); ok {
+		log.WithField("scene_id", g.sceneManager.Current()).Debug("Calling OnExit for active scene")
+		activeScene.OnExit()
+	}
+
+	g.frames.Stop()
 
 	log.Info("Game shutdown complete")
 	// Exit
@@ -92,6 +168,12 @@ func RunGame(g *Game) {
 	ebiten.SetWindowTitle(WINDOW_TITLE)
 	log.WithField("title", WINDOW_TITLE).Debug("Set window title")
 
+	// Scenes only draw when Dirty(), so the screen must keep its previous
+	// contents between those draws rather than being cleared to black
+	// every frame regardless.
+	ebiten.SetScreenClearedEveryFrame(false)
+	log.Debug("Disabled automatic per-frame screen clearing for draw-on-demand rendering")
+
 	// Set window size BEFORE setting fullscreen
 	ebiten.SetWindowSize(g.Width, g.Height)
 	log.WithFields(log.Fields{
@@ -99,8 +181,8 @@ func RunGame(g *Game) {
 		"height": g.Height,
 	}).Debug("Set window size")
 
-	// Set fullscreen if specified in config
-	if g.Config.Render.Window.Fullscreen {
+	// Set fullscreen if specified in config or overridden via -fullscreen
+	if g.Config.Render.Window.Fullscreen || g.startup.Fullscreen {
 		ebiten.SetFullscreen(true)
 		log.Info("Set window to fullscreen mode")
 	} else {
@@ -127,67 +209,178 @@ func RunGame(g *Game) {
 }
 
 func (g *Game) Update() error {
+	// The console gets first look at input every tick, so a keystroke
+	// that opens or types into it never also reaches the active scene.
+	g.console.Update()
+
+	activeSceneId := g.sceneManager.Current()
+	activeScene, sceneExists := g.sceneManager.Get(activeSceneId)
+
+	// Only the active, non-transitioning scene receives events - while a
+	// transition plays, the outgoing/incoming scenes are frozen on their
+	// snapshot (see SceneManager.Begin/Advance) so there's no one sensible
+	// to dispatch to. The bus itself still publishes either way, so other
+	// subscribers (audio, networking) keep seeing every event.
+	var eventScene scenes.Scene
+	if sceneExists && !g.sceneManager.Transitioning() && !g.console.Visible() {
+		eventScene = activeScene
+	}
+	if g.dispatchInputEvents(eventScene) {
+		g.inputDirty = true
+		g.frames.Request()
+	}
+
 	// Check for shutdown or escape key
 	if g.shutdown {
 		log.Debug("Shutdown flag detected, stopping game")
 		return StopGame(g)
 	}
 
-	if ebiten.IsKeyPressed(ebiten.KeyEscape) {
+	if g.startup.EscapeExits && ebiten.IsKeyPressed(ebiten.KeyEscape) {
 		log.Debug("Escape key pressed, stopping game")
 		return StopGame(g)
 	}
 
+	// While the console is open, it owns input entirely - the scene
+	// underneath is frozen (no Update/FixedUpdate) until it closes again.
+	if g.console.Visible() {
+		return nil
+	}
+
+	// While a transition effect is playing, just advance its timer; the
+	// outgoing/incoming scenes stay frozen on their snapshot until it
+	// completes (see SceneManager.Begin/Advance).
+	if g.sceneManager.Transitioning() {
+		g.sceneManager.Advance(time.Second / time.Duration(ebiten.TPS()))
+		return nil
+	}
+
 	// Update current scene
-	activeScene := g.availableScenes[g.activeSceneId]
-	nextSceneId := activeScene.Update()
+	if !sceneExists {
+		log.WithField("scene_id", activeSceneId).Error("Active scene is not registered, stopping game")
+		return StopGame(g)
+	}
+
+	// Run any fixed-timestep simulation (see scenes.FixedUpdater) before
+	// the scene's own variable-rate Update, so Update sees this frame's
+	// settled physics state rather than last frame's.
+	now := time.Now()
+	frameDelta := now.Sub(g.lastTick)
+	g.lastTick = now
+	if fixedScene, ok := activeScene.(scenes.FixedUpdater); ok {
+		steps := g.physicsAccum.Advance(frameDelta)
+		for i := 0; i < steps; i++ {
+			fixedScene.FixedUpdate(physics.Timestep)
+		}
+	}
+
+	result := activeScene.Update()
 
 	// Handle scene transitions
-	if nextSceneId != g.activeSceneId {
+	if result.Next != activeSceneId {
 		log.WithFields(log.Fields{
-			"from_scene": g.activeSceneId,
-			"to_scene":   nextSceneId,
+			"from_scene": activeSceneId,
+			"to_scene":   result.Next,
 		}).Info("Scene transition detected")
 
-		if nextSceneId == scenes.ExitSceneId {
+		if result.Next == scenes.ExitSceneId {
 			log.Info("Exit scene requested, stopping game")
 			return StopGame(g)
 		}
 
-		// Check if target scene exists
-		nextScene, exists := g.availableScenes[nextSceneId]
-		if !exists {
-			log.WithField("scene_id", nextSceneId).Error("Requested scene does not exist, staying, launch Error Scene")
-			// Log g.availableScenes content
-			for id := range g.availableScenes {
-				log.Debugf("scene_id: %v", id)
-			}
+		if _, exists := g.sceneManager.Get(result.Next); !exists {
+			log.WithField("scene_id", result.Next).Error("Requested scene does not exist, stopping game")
 			return StopGame(g)
 		}
 
-		log.WithField("scene_id", g.activeSceneId).Debug("Calling OnExit for current scene")
-		activeScene.OnExit()
+		g.sceneManager.Begin(result.Next, result.Transition)
+	}
 
-		g.activeSceneId = nextSceneId
+	return nil
+}
 
-		if !nextScene.IsLoaded() {
-			log.WithField("scene_id", nextSceneId).Debug("Loading scene for first time")
-			nextScene.FirstLoad()
+// dispatchInputEvents diffs polled input against the previous frame (using
+// inpututil for edge-triggered keys/buttons) and publishes a discrete
+// events.Event for each change on the bus, so non-scene subscribers
+// (audio, networking) see it too. If scene is non-nil, each event is also
+// forwarded to scene.HandleEvent, ahead of the scene's own polling-based
+// Update.
+//
+// It reports whether any input activity occurred, so Draw can redraw even
+// if the active scene hasn't flipped its own Dirty flag yet (e.g. the very
+// frame a key is pressed, before Update has had a chance to react to it).
+func (g *Game) dispatchInputEvents(scene scenes.Scene) bool {
+	occurred := false
+	dispatch := func(ev events.Event) {
+		occurred = true
+		g.bus.Publish(ev)
+		if scene != nil {
+			scene.HandleEvent(ev)
 		}
+	}
 
-		log.WithField("scene_id", nextSceneId).Debug("Calling OnEnter for new scene")
-		nextScene.OnEnter()
+	for _, key := range inpututil.AppendJustPressedKeys(nil) {
+		dispatch(events.KeyDownEvent{Key: key})
+	}
+	for _, key := range inpututil.AppendJustReleasedKeys(nil) {
+		dispatch(events.KeyUpEvent{Key: key})
+	}
 
-		log.WithField("active_scene", g.activeSceneId).Info("Scene transition complete")
+	x, y := ebiten.CursorPosition()
+	for _, button := range []ebiten.MouseButton{ebiten.MouseButtonLeft, ebiten.MouseButtonMiddle, ebiten.MouseButtonRight} {
+		if inpututil.IsMouseButtonJustPressed(button) {
+			dispatch(events.MouseDownEvent{Button: button, X: x, Y: y})
+		}
+		if inpututil.IsMouseButtonJustReleased(button) {
+			dispatch(events.MouseUpEvent{Button: button, X: x, Y: y})
+		}
 	}
 
-	return nil
+	if wheelX, wheelY := ebiten.Wheel(); wheelX != 0 || wheelY != 0 {
+		dispatch(events.WheelEvent{DX: wheelX, DY: wheelY})
+	}
+
+	if x != g.lastCursorX || y != g.lastCursorY {
+		g.lastCursorX, g.lastCursorY = x, y
+		dispatch(events.MouseMoveEvent{X: x, Y: y})
+	}
+
+	return occurred
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
-	// Draw current scene
-	activeScene := g.availableScenes[g.activeSceneId]
-	activeScene.Draw(screen)
+	// Mid-transition, keep compositing every frame until it completes.
+	// Otherwise, draw the active scene only when something actually
+	// changed: it reports itself dirty, or input arrived since the last
+	// frame. This is what lets an idle title screen stop costing a
+	// redraw every frame (see FrameScheduler for the companion
+	// ScheduleFrame throttle).
+	if g.sceneManager.Transitioning() {
+		g.sceneManager.Draw(screen)
+		g.frames.Request()
+	} else if activeScene, ok := g.sceneManager.Get(g.sceneManager.Current()); ok {
+		if activeScene.Dirty() || g.inputDirty {
+			activeScene.Draw(screen)
+			g.inputDirty = false
+		}
+	}
+
+	if g.startup.DebugFPS {
+		fps := fmt.Sprintf("FPS: %0.2f / TPS: %0.2f", ebiten.ActualFPS(), ebiten.ActualTPS())
+		ebitenutil.DebugPrintAt(screen, fps, 4, 4)
+	}
+
+	if g.console.Visible() {
+		drawConsoleOverlay(screen, g.console)
+		g.frames.Request()
+	}
+
+	if g.screenshotRequested {
+		g.screenshotRequested = false
+		if err := takeScreenshot(screen); err != nil {
+			log.WithError(err).Warn("Failed to save screenshot")
+		}
+	}
 }
 
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
@@ -199,6 +392,7 @@ func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
 			"game_width":     g.Width,
 			"game_height":    g.Height,
 		}).Debug("Layout size mismatch detected")
+		g.bus.Publish(events.WindowResizeEvent{Width: outsideWidth, Height: outsideHeight})
 	}
 	return g.Width, g.Height
 }
@@ -0,0 +1,83 @@
+package scenes
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	log "github.com/sirupsen/logrus"
+
+	"app/assets"
+	"app/render/scenes/text"
+)
+
+// AssetStore lazily loads and caches images from disk so multiple scenes
+// can share the same texture instead of each decoding its own copy.
+type AssetStore struct {
+	mu     sync.Mutex
+	images map[string]*ebiten.Image
+	font   *text.Font
+}
+
+// NewAssetStore creates an empty asset cache.
+func NewAssetStore() *AssetStore {
+	return &AssetStore{
+		images: make(map[string]*ebiten.Image),
+	}
+}
+
+// LoadImage returns the cached image for path, decoding and caching it on
+// first use. Subsequent calls with the same path are free.
+func (a *AssetStore) LoadImage(path string) (*ebiten.Image, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if img, ok := a.images[path]; ok {
+		return img, nil
+	}
+
+	img, _, err := ebitenutil.NewImageFromFile(path)
+	if err != nil {
+		// Fall back to the bundled copy in assets.FS (relative to
+		// assets/), so images that ship inside the binary still load
+		// without an assets/ directory alongside it.
+		if embedded, embeddedErr := loadEmbeddedImage(path); embeddedErr == nil {
+			a.images[path] = embedded
+			return embedded, nil
+		}
+		log.WithError(err).WithField("path", path).Warn("Failed to load image asset")
+		return nil, err
+	}
+
+	a.images[path] = img
+	return img, nil
+}
+
+// loadEmbeddedImage decodes path's bundled copy from assets.FS.
+func loadEmbeddedImage(path string) (*ebiten.Image, error) {
+	file, err := assets.FS.Open(strings.TrimPrefix(path, "assets/"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := ebitenutil.NewImageFromReader(file)
+	if err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+// Font returns the shared bitmap font used for scene UI text, baking it
+// on first use so every scene draws with the same atlas texture instead
+// of each keeping its own copy.
+func (a *AssetStore) Font() *text.Font {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.font == nil {
+		a.font = text.DefaultFont()
+	}
+	return a.font
+}
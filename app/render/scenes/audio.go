@@ -0,0 +1,268 @@
+package scenes
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+	log "github.com/sirupsen/logrus"
+
+	"app/assets"
+	"app/common"
+)
+
+// audioSampleRate is the sample rate every decoded sound is resampled to,
+// matching the shared audio.Context so sounds can be mixed together.
+const audioSampleRate = 44100
+
+// AudioRegistry wraps an ebiten audio.Context and lazily loads named OGG/WAV
+// sounds, so scenes can play a sound by name without managing decoders or
+// players themselves. It's also the master-volume owner: SetVolume (and
+// IncreaseVolume/DecreaseVolume) clamp to [0,1] and, if constructed with a
+// *common.Config, persist the result to Config.Audio.Volume.
+type AudioRegistry struct {
+	context *audio.Context
+	config  *common.Config // optional; nil means volume isn't persisted
+
+	mu          sync.Mutex
+	volume      float64
+	sources     map[string]string
+	players     map[string]*audio.Player // one-shot players, from Play
+	loopPlayers map[string]*audio.Player // looping players, from Loop
+}
+
+// NewAudioRegistry creates an audio subsystem at 44100 Hz. The initial
+// master volume and every future SetVolume/IncreaseVolume/DecreaseVolume
+// call are read from and persisted to config.Audio.Volume.
+func NewAudioRegistry(config *common.Config) *AudioRegistry {
+	return &AudioRegistry{
+		context:     audio.NewContext(audioSampleRate),
+		config:      config,
+		volume:      clampVolume(config.Audio.Volume),
+		sources:     make(map[string]string),
+		players:     make(map[string]*audio.Player),
+		loopPlayers: make(map[string]*audio.Player),
+	}
+}
+
+// NewDefaultAudioRegistry creates an audio subsystem and registers the
+// shared menu/SFX sounds (and, outside js/wasm builds, the heavier voice
+// clips) so callers get a ready-to-use registry without duplicating the
+// asset paths at every call site.
+func NewDefaultAudioRegistry(config *common.Config) *AudioRegistry {
+	reg := NewAudioRegistry(config)
+	reg.Register("menu_move", "assets/audio/sfx/menu_move.ogg")
+	reg.Register("menu_confirm", "assets/audio/sfx/menu_confirm.ogg")
+	reg.Register("click", "assets/audio/sfx/click.wav")
+	// merge has no dedicated asset yet; it reuses the click SFX as a
+	// stand-in until a real one is recorded.
+	reg.Register("merge", "assets/audio/sfx/click.wav")
+	reg.Register("menu_music", "assets/audio/music/menu_theme.ogg")
+	registerVoiceClips(reg)
+	return reg
+}
+
+// Register associates a sound name with an OGG/WAV file path. The file
+// isn't read until the sound is first played. path is tried on disk first,
+// falling back to the bundled copy in assets.FS (see decodeAudioFile) if
+// it isn't found there.
+func (a *AudioRegistry) Register(name, path string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.sources[name] = path
+}
+
+// Play plays the named sound once from the start, decoding and caching its
+// player on first use. Unregistered or unreadable sounds are logged and
+// skipped rather than treated as fatal.
+func (a *AudioRegistry) Play(name string) {
+	player, err := a.player(name)
+	if err != nil {
+		log.WithError(err).WithField("sound", name).Warn("Failed to play sound")
+		return
+	}
+	player.Rewind()
+	player.Play()
+}
+
+// Loop starts the named sound looping indefinitely, if it isn't already
+// playing. Use Stop to end it.
+func (a *AudioRegistry) Loop(name string) {
+	player, err := a.loopPlayer(name)
+	if err != nil {
+		log.WithError(err).WithField("sound", name).Warn("Failed to loop sound")
+		return
+	}
+	if !player.IsPlaying() {
+		player.Play()
+	}
+}
+
+// Stop pauses and rewinds the named sound, whether it was started with
+// Play or Loop. It's a no-op if the sound was never played.
+func (a *AudioRegistry) Stop(name string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if player, ok := a.players[name]; ok && player.IsPlaying() {
+		player.Pause()
+		player.Rewind()
+	}
+	if player, ok := a.loopPlayers[name]; ok && player.IsPlaying() {
+		player.Pause()
+		player.Rewind()
+	}
+}
+
+func (a *AudioRegistry) player(name string) (*audio.Player, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if player, ok := a.players[name]; ok {
+		return player, nil
+	}
+
+	data, err := a.decode(name)
+	if err != nil {
+		return nil, err
+	}
+
+	player := a.context.NewPlayerFromBytes(data)
+	player.SetVolume(a.volume)
+	a.players[name] = player
+	return player, nil
+}
+
+func (a *AudioRegistry) loopPlayer(name string) (*audio.Player, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if player, ok := a.loopPlayers[name]; ok {
+		return player, nil
+	}
+
+	data, err := a.decode(name)
+	if err != nil {
+		return nil, err
+	}
+
+	loop := audio.NewInfiniteLoop(bytes.NewReader(data), int64(len(data)))
+	player, err := a.context.NewPlayer(loop)
+	if err != nil {
+		return nil, err
+	}
+	player.SetVolume(a.volume)
+	a.loopPlayers[name] = player
+	return player, nil
+}
+
+// decode resolves name to its registered path and decodes it. Callers must
+// hold a.mu.
+func (a *AudioRegistry) decode(name string) ([]byte, error) {
+	path, ok := a.sources[name]
+	if !ok {
+		return nil, fmt.Errorf("sound %q is not registered", name)
+	}
+	return decodeAudioFile(path)
+}
+
+// Volume returns the current master volume (0-1).
+func (a *AudioRegistry) Volume() float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.volume
+}
+
+// SetVolume updates the master volume, clamped to [0,1], applies it to
+// every player already loaded, and (if this registry was constructed with
+// a *common.Config) persists it to Config.Audio.Volume.
+func (a *AudioRegistry) SetVolume(volume float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.volume = clampVolume(volume)
+	for _, player := range a.players {
+		player.SetVolume(a.volume)
+	}
+	for _, player := range a.loopPlayers {
+		player.SetVolume(a.volume)
+	}
+	if a.config != nil {
+		a.config.Audio.Volume = a.volume
+	}
+}
+
+// IncreaseVolume raises the master volume by d (clamped to [0,1]); see
+// SetVolume.
+func (a *AudioRegistry) IncreaseVolume(d float64) {
+	a.mu.Lock()
+	volume := a.volume + d
+	a.mu.Unlock()
+	a.SetVolume(volume)
+}
+
+// DecreaseVolume lowers the master volume by d (clamped to [0,1]); see
+// SetVolume.
+func (a *AudioRegistry) DecreaseVolume(d float64) {
+	a.IncreaseVolume(-d)
+}
+
+func clampVolume(volume float64) float64 {
+	if volume < 0 {
+		return 0
+	}
+	if volume > 1 {
+		return 1
+	}
+	return volume
+}
+
+// decodeAudioFile reads path and decodes it to raw PCM bytes at
+// audioSampleRate, picking the vorbis or wav decoder from its extension.
+// path is read from disk first; if it isn't found there, the bundled copy
+// under assets.FS is tried instead (see app/assets), so sounds that ship
+// inside the binary still work without an assets/ directory alongside it.
+func decodeAudioFile(path string) ([]byte, error) {
+	file, err := openAudioFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var stream io.Reader
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ogg":
+		stream, err = vorbis.DecodeWithSampleRate(audioSampleRate, file)
+	case ".wav":
+		stream, err = wav.DecodeWithSampleRate(audioSampleRate, file)
+	default:
+		return nil, fmt.Errorf("unsupported audio format: %s", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(stream)
+}
+
+// openAudioFile opens path on disk, falling back to its bundled copy in
+// assets.FS (relative to assets/) if it isn't found there.
+func openAudioFile(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err == nil {
+		return file, nil
+	}
+
+	embedded, embeddedErr := assets.FS.Open(strings.TrimPrefix(path, "assets/"))
+	if embeddedErr != nil {
+		return nil, err
+	}
+	return embedded, nil
+}
@@ -0,0 +1,80 @@
+// Package background provides tiled, scrolling background layers that can
+// be composited together to fake depth (parallax) behind a scene.
+package background
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Velocity is a 2D scroll speed in pixels per tick.
+type Velocity struct {
+	X, Y float64
+}
+
+// BackgroundLayer tiles a single texture across the destination image and
+// scrolls it at a constant velocity. Stacking several layers with
+// different velocities and alphas produces a parallax effect.
+type BackgroundLayer struct {
+	Image    *ebiten.Image
+	Velocity Velocity
+	Alpha    float64
+
+	offsetX, offsetY float64
+}
+
+// NewBackgroundLayer creates a layer that tiles image and scrolls it by
+// velocity every Update, drawn at the given alpha (0-1).
+func NewBackgroundLayer(image *ebiten.Image, velocity Velocity, alpha float64) *BackgroundLayer {
+	return &BackgroundLayer{
+		Image:    image,
+		Velocity: velocity,
+		Alpha:    alpha,
+	}
+}
+
+// Update advances the layer's scroll offset by one tick.
+func (l *BackgroundLayer) Update() {
+	l.offsetX += l.Velocity.X
+	l.offsetY += l.Velocity.Y
+}
+
+// SetOffset pins the layer's scroll offset directly, as an alternative to
+// accumulating it via Update's constant Velocity - e.g. to drive a plane
+// from a camera's position (scaled by depth) instead of an autonomous
+// scroll. Don't mix this with Update on the same layer; whichever runs
+// last each tick wins.
+func (l *BackgroundLayer) SetOffset(x, y float64) {
+	l.offsetX = x
+	l.offsetY = y
+}
+
+// Draw tiles the layer across dst, wrapping the scroll offset into the
+// tile's dimensions so it loops seamlessly.
+func (l *BackgroundLayer) Draw(dst *ebiten.Image) {
+	if l.Image == nil {
+		return
+	}
+
+	tileW, tileH := l.Image.Bounds().Dx(), l.Image.Bounds().Dy()
+	if tileW == 0 || tileH == 0 {
+		return
+	}
+
+	dstW, dstH := dst.Bounds().Dx(), dst.Bounds().Dy()
+
+	startX := math.Mod(l.offsetX, float64(tileW)) - float64(tileW)
+	startY := math.Mod(l.offsetY, float64(tileH)) - float64(tileH)
+
+	op := &ebiten.DrawImageOptions{}
+	op.ColorScale.ScaleAlpha(float32(l.Alpha))
+
+	for y := startY; y < float64(dstH); y += float64(tileH) {
+		for x := startX; x < float64(dstW); x += float64(tileW) {
+			op.GeoM.Reset()
+			op.GeoM.Translate(x, y)
+			dst.DrawImage(l.Image, op)
+		}
+	}
+}
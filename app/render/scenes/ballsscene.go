@@ -6,18 +6,43 @@ import (
 	"image/color"
 	"math"
 	"math/rand"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"app/console"
+	"app/net"
+	"app/physics"
 )
 
 const (
 	BALL_DENSITY = 0.001 // global density parameter (arbitrary units)
 	SPAWN_RATE   = 5     // frames between spawns when holding right mouse
 	gridCellSize = 100   // pixels
+
+	// maxTravelPerSubstep bounds how far the fastest ball may move in a
+	// single substep, in pixels. handleCollisions splits a tick into
+	// enough substeps to respect this even when a large/fast ball would
+	// otherwise cross several cells - and potentially a whole smaller
+	// ball - between one frame and the next.
+	maxTravelPerSubstep = 50
+	maxSubsteps         = 8
 )
 
+func init() {
+	RegisterScene(SceneDescriptor{
+		ID:   BallsSceneId,
+		Name: "Balls Physics Demo",
+		Factory: func(deps *Deps) Scene {
+			return NewBallsScene(deps)
+		},
+		ShowInMenu: true,
+		MenuOrder:  1,
+	})
+}
+
 type Ball struct {
 	X, Y   float32
 	VX, VY float32
@@ -25,11 +50,8 @@ type Ball struct {
 	Color  color.Color
 }
 
-type gridCell struct {
-	indices []int
-}
-
 type BallsScene struct {
+	BaseScene
 	loaded bool
 
 	// For left mouse interaction
@@ -43,13 +65,103 @@ type BallsScene struct {
 	// BallsScene
 	Balls []Ball
 	deps  *Deps
+
+	// grid and checked are reused across FixedUpdate calls (see
+	// handleCollisions) instead of being reallocated every tick.
+	grid    *physics.SpatialHash
+	checked map[[2]int]struct{}
+
+	// physEnabled and gridDebug are exposed to the dev console (see
+	// Cvars) as phys_enabled/grid_debug. density replaces the BALL_DENSITY
+	// constant as ball.density, so mass can be retuned without a rebuild.
+	physEnabled bool
+	gridDebug   bool
+	density     float64
 }
 
 func NewBallsScene(deps *Deps) *BallsScene {
 	return &BallsScene{
-		loaded: false,
-		Balls:  []Ball{},
-		deps:   deps,
+		loaded:      false,
+		Balls:       []Ball{},
+		deps:        deps,
+		grid:        physics.NewSpatialHash(gridCellSize),
+		checked:     make(map[[2]int]struct{}),
+		physEnabled: true,
+		density:     BALL_DENSITY,
+	}
+}
+
+func (s *BallsScene) GetName() string {
+	return "Balls Physics Demo"
+}
+
+// Cvars exposes BallsScene's tunables to the dev console (see
+// app/console). It's called once whenever this scene becomes active, not
+// every frame.
+func (s *BallsScene) Cvars() map[string]*console.Cvar {
+	return map[string]*console.Cvar{
+		"phys_enabled":  console.NewBoolCvar("phys_enabled", &s.physEnabled),
+		"grid_debug":    console.NewBoolCvar("grid_debug", &s.gridDebug),
+		"balls.density": console.NewFloatCvar("balls.density", &s.density),
+	}
+}
+
+// Spawn adds n random balls at random positions, for the console's
+// `spawn <n>` command.
+func (s *BallsScene) Spawn(n int) {
+	width := float32(s.deps.Config.Render.Window.Width)
+	height := float32(s.deps.Config.Render.Window.Height)
+	for i := 0; i < n; i++ {
+		x := rand.Float32() * width
+		y := rand.Float32() * height
+		size := float32(5 + rand.Intn(40))
+		s.Balls = append(s.Balls, newRandomBall(x, y, size))
+	}
+}
+
+// Clear removes every ball, for the console's `clear` command.
+func (s *BallsScene) Clear() {
+	s.Balls = s.Balls[:0]
+}
+
+// Entities reports the current balls for the online sandbox (see
+// app/net.Server.Broadcast). A ball's slice index doubles as its net.ID:
+// BallsScene only ever appends or clears entirely, never removes a single
+// ball, so indices stay stable tick to tick the way net.Source requires.
+func (s *BallsScene) Entities() []net.Entity {
+	entities := make([]net.Entity, len(s.Balls))
+	for i, b := range s.Balls {
+		r, g, bl, _ := b.Color.RGBA()
+		entities[i] = net.Entity{
+			ID:     uint32(i),
+			X:      b.X,
+			Y:      b.Y,
+			VX:     b.VX,
+			VY:     b.VY,
+			Radius: b.Radius,
+			R:      uint8(r >> 8),
+			G:      uint8(g >> 8),
+			B:      uint8(bl >> 8),
+		}
+	}
+	return entities
+}
+
+// ApplySnapshot replaces the local balls with the online sandbox host's
+// latest broadcast state (see app/net.Client.Entities), for a joining
+// client - the host is authoritative, so this is a wholesale replace, not
+// a merge.
+func (s *BallsScene) ApplySnapshot(entities []net.Entity) {
+	s.Balls = s.Balls[:0]
+	for _, e := range entities {
+		s.Balls = append(s.Balls, Ball{
+			X:      e.X,
+			Y:      e.Y,
+			VX:     e.VX,
+			VY:     e.VY,
+			Radius: e.Radius,
+			Color:  color.RGBA{R: e.R, G: e.G, B: e.B, A: 255},
+		})
 	}
 }
 
@@ -83,6 +195,9 @@ func randomColor() color.Color {
 func (s *BallsScene) Draw(screen *ebiten.Image) {
 	// Draw demo name
 	ebitenutil.DebugPrintAt(screen, "Balls Physics Demo (press Q to return)", 40, 40)
+	if s.gridDebug {
+		s.drawGridDebug(screen)
+	}
 	// Draw all balls
 	for _, b := range s.Balls {
 		vector.DrawFilledCircle(screen, b.X, b.Y, b.Radius, b.Color, false)
@@ -102,6 +217,21 @@ func (s *BallsScene) Draw(screen *ebiten.Image) {
 	ebitenutil.DebugPrintAt(screen, fpsStr, 10, 10)
 }
 
+// drawGridDebug overlays the broadphase spatial hash's cell boundaries,
+// for the console's grid_debug cvar.
+func (s *BallsScene) drawGridDebug(screen *ebiten.Image) {
+	width := float64(s.deps.Config.Render.Window.Width)
+	height := float64(s.deps.Config.Render.Window.Height)
+	cell := s.grid.CellSize()
+	col := color.RGBA{255, 255, 255, 60}
+	for x := 0.0; x < width; x += cell {
+		ebitenutil.DrawLine(screen, x, 0, x, height, col)
+	}
+	for y := 0.0; y < height; y += cell {
+		ebitenutil.DrawLine(screen, 0, y, width, y, col)
+	}
+}
+
 func (s *BallsScene) FirstLoad() {
 	s.loaded = true
 }
@@ -116,17 +246,11 @@ func (s *BallsScene) OnEnter() {
 func (s *BallsScene) OnExit() {
 }
 
-func (s *BallsScene) Update() SceneId {
+func (s *BallsScene) Update() SceneTransition {
 	// Q to return to StartScene
 	if ebiten.IsKeyPressed(ebiten.KeyQ) {
-		return StartSceneId
+		return NextScene(StartSceneId, Transition{Kind: TransitionFade, Duration: 400 * time.Millisecond})
 	}
-	// Get actual window size from config
-	width := float32(s.deps.Config.Render.Window.Width)
-	height := float32(s.deps.Config.Render.Window.Height)
-	// Wall and balls collision logic
-	s.handleCollisions(width, height)
-
 	// Left mouse interaction (existing)
 	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
 		if !s.mousePressed {
@@ -143,6 +267,7 @@ func (s *BallsScene) Update() SceneId {
 		}
 		newBall := newRandomBall(float32(x), float32(y), size)
 		s.Balls = append(s.Balls, newBall)
+		s.deps.Audio.Play("click")
 		s.mousePressed = false
 	}
 	// Right mouse interaction: hold to spawn random balls at SPAWN_RATE
@@ -157,24 +282,79 @@ func (s *BallsScene) Update() SceneId {
 			size := float32(5 + rand.Intn(100))
 			newBall := newRandomBall(float32(x), float32(y), size)
 			s.Balls = append(s.Balls, newBall)
+			s.deps.Audio.Play("click")
 		}
 	} else {
 		s.rightMousePressed = false
 		s.rightMouseSpawnTick = 0
 	}
-	return BallsSceneId
+	return NextScene(BallsSceneId, Transition{})
+}
+
+// FixedUpdate advances ball motion and collisions by one physics.Timestep,
+// independent of Ebiten's variable per-frame call rate (see
+// physics.Accumulator). It runs handleCollisions over one or more
+// substeps so a ball moving fast relative to its neighbors' radius can't
+// tunnel through them between one substep and the next.
+func (s *BallsScene) FixedUpdate(dt time.Duration) {
+	// A joining client just mirrors whatever the host last broadcast -
+	// the host is authoritative, so this scene doesn't also simulate
+	// locally while joined (see app/net.Client).
+	if s.deps.Net.Joined() {
+		s.ApplySnapshot(s.deps.Net.Client.Entities())
+		return
+	}
+
+	if !s.physEnabled {
+		return
+	}
+
+	width := float32(s.deps.Config.Render.Window.Width)
+	height := float32(s.deps.Config.Render.Window.Height)
+
+	substeps := s.requiredSubsteps()
+	for i := 0; i < substeps; i++ {
+		s.handleCollisions(width, height, substeps)
+	}
+
+	if s.deps.Net.Hosting() {
+		s.deps.Net.Server.Broadcast(s)
+	}
+}
+
+// requiredSubsteps returns how many substeps this tick needs so that even
+// the fastest ball travels at most maxTravelPerSubstep pixels in a single
+// substep - the usual way to avoid tunneling without shrinking the fixed
+// timestep itself.
+func (s *BallsScene) requiredSubsteps() int {
+	maxSpeed := float32(0)
+	for _, b := range s.Balls {
+		speed := float32(math.Hypot(float64(b.VX), float64(b.VY)))
+		if speed > maxSpeed {
+			maxSpeed = speed
+		}
+	}
+	substeps := int(math.Ceil(float64(maxSpeed) / maxTravelPerSubstep))
+	if substeps < 1 {
+		substeps = 1
+	}
+	if substeps > maxSubsteps {
+		substeps = maxSubsteps
+	}
+	return substeps
 }
 
-// --- Scalable collision logic using a uniform grid ---
-func (s *BallsScene) handleCollisions(width, height float32) {
-	grid := make(map[[2]int]*gridCell)
+// --- Scalable collision logic using a reusable spatial hash ---
+func (s *BallsScene) handleCollisions(width, height float32, substeps int) {
+	s.grid.Reset()
 
-	// Update ball positions and build grid in a single loop
+	// Update ball positions (by a fraction of a tick, per substep) and
+	// insert them into the grid in a single loop.
 	for i := range s.Balls {
 		b := &s.Balls[i] // Get pointer to the actual ball
 		// Update position
-		b.X += b.VX
-		b.Y += b.VY
+		b.X += b.VX / float32(substeps)
+		b.Y += b.VY / float32(substeps)
 
 		// Check wall collisions
 		if b.X < b.Radius {
@@ -194,71 +374,80 @@ func (s *BallsScene) handleCollisions(width, height float32) {
 			b.VY = -b.VY
 		}
 
-		// Build grid
-		minX := int((b.X - b.Radius) / gridCellSize)
-		maxX := int((b.X + b.Radius) / gridCellSize)
-		minY := int((b.Y - b.Radius) / gridCellSize)
-		maxY := int((b.Y + b.Radius) / gridCellSize)
+		// Insert into every cell the ball's bounding box overlaps
+		minX, minY := s.grid.Cell(float64(b.X-b.Radius), float64(b.Y-b.Radius))
+		maxX, maxY := s.grid.Cell(float64(b.X+b.Radius), float64(b.Y+b.Radius))
 		for gx := minX; gx <= maxX; gx++ {
 			for gy := minY; gy <= maxY; gy++ {
-				key := [2]int{gx, gy}
-				if grid[key] == nil {
-					grid[key] = &gridCell{}
-				}
-				grid[key].indices = append(grid[key].indices, i)
+				s.grid.Insert(gx, gy, i)
 			}
 		}
 	}
-	// Check collisions only within each cell
-	checked := make(map[[2]int]struct{})
-	for _, cell := range grid {
-		indices := cell.indices
+
+	// Check collisions only within each occupied cell
+	for key := range s.checked {
+		delete(s.checked, key)
+	}
+	s.grid.ForEachBucket(func(indices []int) {
 		for i := 0; i < len(indices); i++ {
 			for j := i + 1; j < len(indices); j++ {
 				i1, i2 := indices[i], indices[j]
 				pair := [2]int{i1, i2}
-				if _, ok := checked[pair]; ok {
+				if _, ok := s.checked[pair]; ok {
 					continue
 				}
-				checked[pair] = struct{}{}
-				b1 := &s.Balls[i1]
-				b2 := &s.Balls[i2]
-				dx := float64(b1.X - b2.X)
-				dy := float64(b1.Y - b2.Y)
-				dist := math.Hypot(dx, dy)
-				minDist := float64(b1.Radius + b2.Radius)
-				if dist < minDist && dist > 0 {
-					// Calculate masses
-					m1 := BALL_DENSITY * math.Pi * math.Pow(float64(b1.Radius), 2)
-					m2 := BALL_DENSITY * math.Pi * math.Pow(float64(b2.Radius), 2)
-					// Normal vector
-					nx := dx / dist
-					ny := dy / dist
-					// Relative velocity
-					dvx := float64(b1.VX - b2.VX)
-					dvy := float64(b1.VY - b2.VY)
-					// Velocity along the normal
-					vn := dvx*nx + dvy*ny
-					if vn > 0 {
-						continue // balls are moving apart
-					}
-					// Impulse scalar
-					impulse := (2 * vn) / (m1 + m2)
-					// Update velocities
-					b1.VX = float32(float64(b1.VX) - impulse*m2*nx)
-					b1.VY = float32(float64(b1.VY) - impulse*m2*ny)
-					b2.VX = float32(float64(b2.VX) + impulse*m1*nx)
-					b2.VY = float32(float64(b2.VY) + impulse*m1*ny)
-					// Optional: separate balls to prevent sticking
-					overlap := minDist - dist
-					b1.X += float32(nx * overlap / 2)
-					b1.Y += float32(ny * overlap / 2)
-					b2.X -= float32(nx * overlap / 2)
-					b2.Y -= float32(ny * overlap / 2)
-				}
+				s.checked[pair] = struct{}{}
+				s.resolveCollision(i1, i2)
 			}
 		}
+	})
+}
+
+// resolveCollision applies an elastic collision impulse between balls i1
+// and i2 if they overlap and are approaching each other.
+func (s *BallsScene) resolveCollision(i1, i2 int) {
+	b1 := &s.Balls[i1]
+	b2 := &s.Balls[i2]
+	dx := float64(b1.X - b2.X)
+	dy := float64(b1.Y - b2.Y)
+	dist := math.Hypot(dx, dy)
+	minDist := float64(b1.Radius + b2.Radius)
+	if dist >= minDist || dist <= 0 {
+		return
+	}
+	// Calculate masses
+	m1 := s.density * math.Pi * math.Pow(float64(b1.Radius), 2)
+	m2 := s.density * math.Pi * math.Pow(float64(b2.Radius), 2)
+	// Normal vector
+	nx := dx / dist
+	ny := dy / dist
+	// Relative velocity
+	dvx := float64(b1.VX - b2.VX)
+	dvy := float64(b1.VY - b2.VY)
+	// Velocity along the normal
+	vn := dvx*nx + dvy*ny
+	if vn > 0 {
+		return // balls are moving apart
 	}
+	// Impulse scalar
+	impulse := (2 * vn) / (m1 + m2)
+	// Update velocities
+	b1.VX = float32(float64(b1.VX) - impulse*m2*nx)
+	b1.VY = float32(float64(b1.VY) - impulse*m2*ny)
+	b2.VX = float32(float64(b2.VX) + impulse*m1*nx)
+	b2.VY = float32(float64(b2.VY) + impulse*m1*ny)
+	// Optional: separate balls to prevent sticking
+	overlap := minDist - dist
+	b1.X += float32(nx * overlap / 2)
+	b1.Y += float32(ny * overlap / 2)
+	b2.X -= float32(nx * overlap / 2)
+	b2.Y -= float32(ny * overlap / 2)
+	s.deps.Audio.Play("click")
 }
 
 var _ Scene = (*BallsScene)(nil)
+var _ FixedUpdater = (*BallsScene)(nil)
+var _ console.CvarSource = (*BallsScene)(nil)
+var _ console.Spawner = (*BallsScene)(nil)
+var _ console.Clearer = (*BallsScene)(nil)
+var _ net.Source = (*BallsScene)(nil)
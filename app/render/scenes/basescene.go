@@ -0,0 +1,20 @@
+package scenes
+
+import "app/events"
+
+// BaseScene provides default implementations of optional Scene behavior.
+// Embed it in a scene struct to pick up these defaults without having to
+// implement every method yourself.
+type BaseScene struct{}
+
+// Dirty reports whether the scene needs to be redrawn. The default always
+// reports dirty, so scenes that don't track their own redraw state keep
+// rendering every frame exactly like before this existed.
+func (BaseScene) Dirty() bool {
+	return true
+}
+
+// HandleEvent discards every event. Override it in your scene to react to
+// discrete input/lifecycle events instead of (or in addition to) polling
+// in Update.
+func (BaseScene) HandleEvent(ev events.Event) {}
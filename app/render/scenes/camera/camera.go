@@ -0,0 +1,159 @@
+// Package camera provides a reusable 2D pan/zoom viewport. Scenes keep
+// their content in world-space coordinates and use Camera to project it
+// onto a screen-space viewport via GeoM, instead of each scene inventing
+// its own ad-hoc scale/translate math.
+package camera
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Vector is a 2D point or offset, in either world or screen space
+// depending on context.
+type Vector struct {
+	X, Y float64
+}
+
+// Bounds clamps a Camera's Position so it never shows past a world-space
+// rectangle, e.g. keeping a viewport from scrolling past a map's edges.
+type Bounds struct {
+	Min, Max Vector
+}
+
+// Camera projects a world-space scene onto a screen-space viewport via
+// pan (Position) and zoom (Zoom), with optional hard target-follow and
+// bounds clamping. Scenes draw their world-space content with GeoM as the
+// base transform, then translate to wherever the viewport sits on the
+// real screen; WorldToScreen/ScreenToWorld convert individual points
+// (e.g. for input hit-testing), and IsWorldRange supports culling
+// off-screen sprites before drawing them.
+type Camera struct {
+	Position Vector
+	Zoom     float64
+
+	// Target, if non-nil, overrides Position every Update call - the
+	// camera hard-follows it rather than easing toward it, unless
+	// Smoothing is also set.
+	Target *Vector
+
+	// Smoothing, if greater than 0, makes Update ease Position toward
+	// Target by this fraction of the remaining distance each call
+	// (e.g. 0.1 closes 10% of the gap per tick) instead of snapping to
+	// it outright. Leave at 0 for the original hard-follow behavior.
+	Smoothing float64
+
+	// Bounds, if non-nil, clamps Position after Target is applied so the
+	// viewport never shows past the given world rectangle.
+	Bounds *Bounds
+
+	// ViewportWidth/ViewportHeight is the on-screen size, in pixels, that
+	// the camera projects into; see SetViewport.
+	ViewportWidth, ViewportHeight float64
+}
+
+// New creates a Camera at the world origin with no zoom applied, sized
+// for a viewportW x viewportH screen-space viewport.
+func New(viewportW, viewportH float64) *Camera {
+	return &Camera{
+		Zoom:           1,
+		ViewportWidth:  viewportW,
+		ViewportHeight: viewportH,
+	}
+}
+
+// SetViewport updates the screen-space size the camera projects into,
+// e.g. when the scene's draw area is resized.
+func (c *Camera) SetViewport(width, height float64) {
+	c.ViewportWidth = width
+	c.ViewportHeight = height
+}
+
+// Update applies Target-following and Bounds-clamping for the current
+// frame. Call it once per tick, after changing Zoom/Target/Bounds and
+// before drawing or projecting points.
+func (c *Camera) Update() {
+	if c.Target != nil {
+		if c.Smoothing > 0 {
+			c.Position.X += (c.Target.X - c.Position.X) * c.Smoothing
+			c.Position.Y += (c.Target.Y - c.Position.Y) * c.Smoothing
+		} else {
+			c.Position = *c.Target
+		}
+	}
+	c.clampToBounds()
+}
+
+func (c *Camera) clampToBounds() {
+	if c.Bounds == nil || c.Zoom <= 0 {
+		return
+	}
+	halfW := c.ViewportWidth / (2 * c.Zoom)
+	halfH := c.ViewportHeight / (2 * c.Zoom)
+	c.Position.X = clampRange(c.Position.X, c.Bounds.Min.X+halfW, c.Bounds.Max.X-halfW)
+	c.Position.Y = clampRange(c.Position.Y, c.Bounds.Min.Y+halfH, c.Bounds.Max.Y-halfH)
+}
+
+// clampRange clamps v to [min, max], or returns their midpoint if the
+// viewport is wider than the bounds (min > max) so there's nowhere valid
+// to clamp to.
+func clampRange(v, min, max float64) float64 {
+	if min > max {
+		return (min + max) / 2
+	}
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// GeoM returns the transform mapping world-space coordinates into this
+// camera's screen-space viewport, centering Position at Zoom
+// magnification. Callers typically use this as the base GeoM for drawing
+// a world-space image, then translate to wherever the viewport sits on
+// the real screen.
+func (c *Camera) GeoM() ebiten.GeoM {
+	var g ebiten.GeoM
+	g.Translate(-c.Position.X, -c.Position.Y)
+	g.Scale(c.Zoom, c.Zoom)
+	g.Translate(c.ViewportWidth/2, c.ViewportHeight/2)
+	return g
+}
+
+// WorldToScreen projects a world-space point into this camera's
+// viewport-local screen coordinates.
+func (c *Camera) WorldToScreen(world Vector) Vector {
+	g := c.GeoM()
+	x, y := g.Apply(world.X, world.Y)
+	return Vector{X: x, Y: y}
+}
+
+// ScreenToWorld is the inverse of WorldToScreen. It errors if the
+// camera's current transform can't be inverted (Zoom is 0).
+func (c *Camera) ScreenToWorld(screen Vector) (Vector, error) {
+	g := c.GeoM()
+	if !g.IsInvertible() {
+		return Vector{}, fmt.Errorf("camera transform is not invertible (zoom=%v)", c.Zoom)
+	}
+	g.Invert()
+	x, y := g.Apply(screen.X, screen.Y)
+	return Vector{X: x, Y: y}, nil
+}
+
+// IsWorldRange reports whether the world-space axis-aligned rectangle
+// [min, max] overlaps this camera's currently visible area, so callers
+// can cull sprites that would draw entirely off-screen.
+func (c *Camera) IsWorldRange(min, max Vector) bool {
+	if c.Zoom <= 0 {
+		return true
+	}
+	halfW := c.ViewportWidth / (2 * c.Zoom)
+	halfH := c.ViewportHeight / (2 * c.Zoom)
+	viewMinX, viewMaxX := c.Position.X-halfW, c.Position.X+halfW
+	viewMinY, viewMaxY := c.Position.Y-halfH, c.Position.Y+halfH
+	return min.X <= viewMaxX && max.X >= viewMinX && min.Y <= viewMaxY && max.Y >= viewMinY
+}
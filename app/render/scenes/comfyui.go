@@ -1,12 +1,18 @@
 package scenes
 
 import (
+	"app/common"
+	"app/render/scenes/camera"
 	"app/services"
+	"app/ui"
 	"fmt"
+	"image"
 	"image/color"
 	"math"
+	"math/rand"
+	"sort"
+	"strconv"
 	"strings"
-	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -16,25 +22,207 @@ import (
 	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
+func init() {
+	RegisterScene(SceneDescriptor{
+		ID:   ComfyUISceneId,
+		Name: "ComfyUI Demo",
+		Factory: func(deps *Deps) Scene {
+			return NewComfyUIScene(deps)
+		},
+		ShowInMenu: true,
+		MenuOrder:  3,
+	})
+}
+
 type ComfyUIScene struct {
+	BaseScene
 	loaded bool
 	deps   *Deps
 
 	// Add your scene-specific fields here
-	// Text input state
-	textInput       string
-	cursorVisible   bool
-	lastCursorBlink time.Time
-	inputActive     bool
+	// Text input widget for the prompt box
+	input *ui.TextInput
 
 	// Animation state for background
 	animationTime float64
 
-	// Image generation state
-	isGenerating   bool
-	generatedImage *ebiten.Image
-	currentPrompt  string
-	resultChannel  <-chan *services.AsyncImageResult
+	// Generation job queue: activeJob is the most recently enqueued job
+	// (drives the spinner/status text), selectedJob is whichever job's
+	// image is shown enlarged (defaults to activeJob once it completes,
+	// but can be any job picked from the gallery).
+	jobs             *services.JobQueue
+	activeJob        *services.Job
+	selectedJob      *services.Job
+	lastActiveStatus services.JobStatus
+	nextSeed         int
+	galleryScroll    int
+
+	// theme holds the resolved (and defaulted) colors/sizes this scene
+	// draws with; see resolveComfyTheme.
+	theme comfyTheme
+
+	// presetNames lists Config.Comfy.Presets' keys, sorted, so the runtime
+	// preset selector has a stable cycling order; activePreset is the
+	// currently selected one, see currentPreset/cyclePreset.
+	presetNames  []string
+	activePreset string
+
+	// imageCamera pans/zooms the selected image within its viewport box;
+	// cameraImageID/cameraFitZoom track which job it's currently fit to,
+	// and cameraDragging/cameraDragLast* track an in-progress middle-mouse
+	// pan. See syncImageCamera/handleImageCameraInput.
+	imageCamera     *camera.Camera
+	cameraImageID   string
+	cameraFitZoom   float64
+	cameraDragging  bool
+	cameraDragLastX int
+	cameraDragLastY int
+
+	// dirty tracks whether the scene needs to be redrawn; see Dirty().
+	dirty      bool
+	frameCount int
+}
+
+// particleTickFrames throttles the particle/spinner animation's dirty
+// signal to roughly 10 FPS (assuming a 60 TPS update rate), instead of
+// marking dirty - and costing a full redraw - on every tick.
+const particleTickFrames = 6
+
+// defaultComfyUIOutputDir is used when Config.ComfyUI.OutputDir isn't set,
+// so the demo scene still persists a job history without requiring config.
+const defaultComfyUIOutputDir = "output/comfyui"
+
+// Layout constants for the scrollable thumbnail gallery strip along the
+// top of the scene.
+const (
+	galleryTop    = 50
+	thumbnailSize = 64
+	thumbnailGap  = 8
+)
+
+// Layout for the preset selector in the top-right corner.
+const (
+	presetBoxWidth  = 260
+	presetBoxHeight = 24
+	presetBoxMargin = 20
+)
+
+// Layout for the generated-image viewport box, and the zoom range a user
+// can reach relative to the image's fit-to-box zoom.
+const (
+	imageViewportMaxWidth  = 700
+	imageViewportMaxHeight = 500
+	imageViewportMinZoom   = 0.5
+	imageViewportMaxZoom   = 8
+)
+
+// comfyTheme holds the resolved colors/sizes ComfyUIScene draws with,
+// computed once in NewComfyUIScene from Config.Theme so the draw methods
+// below never have to fall back to a zero value mid-frame.
+type comfyTheme struct {
+	gradientTop    color.RGBA
+	gradientBottom color.RGBA
+	accent         color.RGBA
+	border         color.RGBA
+	inputBox       color.RGBA
+	particleCount  int
+	fontScale      int
+}
+
+// defaultComfyTheme is the scene's original hard-coded look, used for any
+// Theme field left blank in config.
+func defaultComfyTheme() comfyTheme {
+	return comfyTheme{
+		gradientTop:    color.RGBA{20, 25, 40, 255},
+		gradientBottom: color.RGBA{40, 20, 60, 255},
+		accent:         color.RGBA{100, 150, 200, 255},
+		border:         color.RGBA{80, 80, 100, 255},
+		inputBox:       color.RGBA{40, 40, 50, 200},
+		particleCount:  50,
+		fontScale:      2,
+	}
+}
+
+// resolveComfyTheme overlays cfg.Theme on top of defaultComfyTheme, so an
+// absent or partial `theme:` section still produces a fully usable theme.
+func resolveComfyTheme(cfg *common.Config) comfyTheme {
+	theme := defaultComfyTheme()
+	if cfg == nil {
+		return theme
+	}
+
+	t := cfg.Theme
+	if c, ok := parseHexColor(t.Background.Top); ok {
+		theme.gradientTop = c
+	}
+	if c, ok := parseHexColor(t.Background.Bottom); ok {
+		theme.gradientBottom = c
+	}
+	if c, ok := parseHexColor(t.Accent); ok {
+		theme.accent = c
+	}
+	if c, ok := parseHexColor(t.BorderColor); ok {
+		theme.border = c
+	}
+	if c, ok := parseHexColor(t.InputBoxColor); ok {
+		theme.inputBox = c
+	}
+	if t.InputOpacity > 0 {
+		theme.inputBox.A = uint8(t.InputOpacity)
+	}
+	if t.ParticleCount > 0 {
+		theme.particleCount = t.ParticleCount
+	}
+	if t.Font.Size > 0 {
+		theme.fontScale = t.Font.Size
+	}
+	return theme
+}
+
+// parseHexColor parses a "#RRGGBB" or "#RRGGBBAA" string into a
+// color.RGBA, defaulting alpha to opaque when not given. It reports
+// ok=false for anything else, so callers can keep their existing default
+// instead of drawing with a garbage color.
+func parseHexColor(s string) (c color.RGBA, ok bool) {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) != 6 && len(s) != 8 {
+		return color.RGBA{}, false
+	}
+
+	value, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.RGBA{}, false
+	}
+
+	if len(s) == 6 {
+		return color.RGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 255}, true
+	}
+	return color.RGBA{R: uint8(value >> 24), G: uint8(value >> 16), B: uint8(value >> 8), A: uint8(value)}, true
+}
+
+// brighten adds rgbDelta to each of c's color channels and alphaDelta to
+// its alpha, clamping at 255, for the input box's "active" highlight.
+func brighten(c color.RGBA, rgbDelta, alphaDelta uint8) color.RGBA {
+	add := func(v, delta uint8) uint8 {
+		if int(v)+int(delta) > 255 {
+			return 255
+		}
+		return v + delta
+	}
+	return color.RGBA{add(c.R, rgbDelta), add(c.G, rgbDelta), add(c.B, rgbDelta), add(c.A, alphaDelta)}
+}
+
+// defaultWorkflowPreset is used whenever the active preset name doesn't
+// resolve to an entry in Config.Comfy.Presets - including when no presets
+// are configured at all - reproducing the scene's original hard-coded
+// generation parameters.
+var defaultWorkflowPreset = common.WorkflowPreset{
+	Workflow:   "default_api.json",
+	Steps:      20,
+	SeedPolicy: common.SeedPolicyFixed,
+	Seed:       42,
+	Size:       512,
+	Ratio:      string(services.ImageRatioLandscape),
 }
 
 func NewComfyUIScene(deps *Deps) *ComfyUIScene {
@@ -48,19 +236,58 @@ func NewComfyUIScene(deps *Deps) *ComfyUIScene {
 		log.WithField("comfyui_running", deps.ComfyUI.IsRunning()).Debug("ComfyUI service status")
 	}
 
+	outputDir := defaultComfyUIOutputDir
+	var cfg *common.Config
+	if deps != nil {
+		cfg = deps.Config
+	}
+	if cfg != nil && cfg.ComfyUI.OutputDir != "" {
+		outputDir = cfg.ComfyUI.OutputDir
+	}
+
+	var presetNames []string
+	if cfg != nil {
+		for name := range cfg.Comfy.Presets {
+			presetNames = append(presetNames, name)
+		}
+		sort.Strings(presetNames)
+	}
+
+	theme := resolveComfyTheme(cfg)
+
 	scene := &ComfyUIScene{
-		loaded:          false,
-		deps:            deps,
-		textInput:       "Enter your prompt here...",
-		cursorVisible:   true,
-		lastCursorBlink: time.Now(),
-		inputActive:     false,
-		animationTime:   0.0,
-		isGenerating:    false,
-		generatedImage:  nil,
-		currentPrompt:   "",
-		resultChannel:   nil,
+		loaded:        false,
+		deps:          deps,
+		animationTime: 0.0,
+		jobs:          services.NewJobQueue(deps.ComfyUI, outputDir),
+		theme:         theme,
+		presetNames:   presetNames,
+		imageCamera:   camera.New(0, 0),
+		dirty:         true,
+	}
+
+	activePreset := ""
+	if cfg != nil {
+		if _, ok := cfg.Comfy.Presets[cfg.Comfy.DefaultPreset]; ok {
+			activePreset = cfg.Comfy.DefaultPreset
+		} else if len(presetNames) > 0 {
+			activePreset = presetNames[0]
+		}
 	}
+	scene.activePreset = activePreset
+	scene.nextSeed = scene.currentPreset().Seed
+
+	scene.input = ui.NewTextInput(ui.TextInputConfig{
+		Font:        deps.Assets.Font(),
+		Scale:       theme.fontScale,
+		MaxWidth:    600,
+		Multiline:   true,
+		Placeholder: "Enter your prompt here...",
+		OnDirty: func() {
+			scene.dirty = true
+			scene.deps.Frames.Request()
+		},
+	})
 
 	log.WithField("scene_address", &scene).Debug("ComfyUI scene created successfully")
 	return scene
@@ -70,76 +297,103 @@ func (s *ComfyUIScene) GetName() string {
 	return "ComfyUI Demo"
 }
 
-func (s *ComfyUIScene) Update() SceneId {
+func (s *ComfyUIScene) Update() SceneTransition {
 	// Update animation time
 	s.animationTime += 1.0 / 60.0 // Assuming 60 FPS
 
-	// Check for async image generation results
-	s.checkImageGenerationResult()
+	// The particle effect and loading spinner only need to redraw a few
+	// times a second to read as animated, so that's the only thing that
+	// keeps an otherwise-idle scene dirty.
+	s.frameCount++
+	if s.frameCount%particleTickFrames == 0 {
+		s.dirty = true
+		s.deps.Frames.Request()
+	}
 
-	// Handle escape key to exit
-	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+	// Pick up job state changes from the queue's background worker; this
+	// is what replaces polling a single result channel now that several
+	// jobs can be in flight/history at once.
+	s.pollActiveJob()
+
+	screenWidth := s.deps.Config.Render.Window.Width
+	screenHeight := s.deps.Config.Render.Window.Height
+	s.syncImageCamera(screenWidth, screenHeight)
+	s.handleImageCameraInput(screenWidth, screenHeight)
+
+	s.handleGalleryInput()
+	s.handlePresetInput()
+
+	// The prompt box owns the keyboard while it's active; otherwise Enter
+	// activates it, Delete cancels the active job, R reuses the selected
+	// job's prompt/seed, Tab cycles the workflow preset, and Escape exits
+	// the scene.
+	switch {
+	case s.input.Active():
+		s.input.Update()
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		s.input.Activate()
+		log.Debug("Text input activated")
+	case inpututil.IsKeyJustPressed(ebiten.KeyDelete):
+		s.cancelActiveJob()
+	case inpututil.IsKeyJustPressed(ebiten.KeyR):
+		s.reuseSelectedJob()
+	case inpututil.IsKeyJustPressed(ebiten.KeyTab):
+		s.cyclePreset()
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
 		log.Debug("Escape key pressed in ComfyUI scene, exiting")
-		return ExitSceneId
-	}
-
-	// Handle text input activation
-	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
-		if s.inputActive {
-			// Submitting text input
-			s.inputActive = false
-			trimmedText := strings.TrimSpace(s.textInput)
-
-			if trimmedText != "" && trimmedText != "Enter your prompt here..." {
-				log.WithField("prompt", trimmedText).Info("Submitting image generation request")
-				s.startImageGeneration(trimmedText)
-			} else {
-				log.Debug("Empty prompt, not generating image")
-				// Add placeholder if empty
-				if trimmedText == "" {
-					s.textInput = "Enter your prompt here..."
-				}
-			}
-		} else {
-			// Activating text input
-			s.inputActive = true
-			log.Debug("Text input activated")
-			// Clear placeholder text when activating
-			if s.textInput == "Enter your prompt here..." {
-				s.textInput = ""
-			}
-		}
+		return NextScene(ExitSceneId, Transition{})
 	}
 
-	// Handle text input when active
-	if s.inputActive {
-		// Handle text input
-		inputChars := ebiten.AppendInputChars(nil)
-		for _, char := range inputChars {
-			if char >= 32 && char <= 126 { // Printable ASCII characters
-				s.textInput += string(char)
-			}
-		}
-
-		// Handle backspace
-		if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(s.textInput) > 0 {
-			s.textInput = s.textInput[:len(s.textInput)-1]
+	select {
+	case prompt := <-s.input.Submitted():
+		trimmedText := strings.TrimSpace(prompt)
+		if trimmedText != "" {
+			log.WithField("prompt", trimmedText).Info("Submitting image generation request")
+			s.startImageGeneration(trimmedText)
+		} else {
+			log.Debug("Empty prompt, not generating image")
 		}
+	default:
+	}
 
-		// Handle cursor blinking
-		if time.Since(s.lastCursorBlink) > 500*time.Millisecond {
-			s.cursorVisible = !s.cursorVisible
-			s.lastCursorBlink = time.Now()
-		}
+	select {
+	case <-s.input.Cancelled():
+		log.Debug("Text input cancelled")
+	default:
 	}
 
 	// Handle input and update scene logic
 	// Return the SceneId for the next scene or current scene
 
-	return ComfyUISceneId
+	return NextScene(ComfyUISceneId, Transition{})
+}
+
+// pollActiveJob notices when the background worker moves activeJob
+// between states (e.g. Pending -> Running -> Done) and redraws/selects
+// accordingly. Jobs are mutated by JobQueue's own goroutine, so this is
+// the scene's only way to learn about a state change short of blocking.
+func (s *ComfyUIScene) pollActiveJob() {
+	if s.activeJob == nil || s.activeJob.Status == s.lastActiveStatus {
+		return
+	}
+
+	s.lastActiveStatus = s.activeJob.Status
+	s.dirty = true
+	s.deps.Frames.Request()
+
+	switch s.activeJob.Status {
+	case services.JobDone:
+		log.WithField("job_id", s.activeJob.ID).Info("Image generation job completed")
+		s.selectedJob = s.activeJob
+	case services.JobFailed:
+		log.WithFields(log.Fields{"job_id": s.activeJob.ID, "error": s.activeJob.Error}).Error("Image generation job failed")
+	case services.JobCancelled:
+		log.WithField("job_id", s.activeJob.ID).Info("Image generation job cancelled")
+	}
 }
 
-// startImageGeneration initiates async image generation
+// startImageGeneration enqueues prompt as a new job on the scene's
+// JobQueue and makes it the active/selected job.
 func (s *ComfyUIScene) startImageGeneration(prompt string) {
 	if s.deps == nil || s.deps.ComfyUI == nil {
 		log.Error("Cannot start image generation: ComfyUI service not available")
@@ -154,73 +408,347 @@ func (s *ComfyUIScene) startImageGeneration(prompt string) {
 		}
 	}
 
-	// Create image request
+	preset := s.currentPreset()
+	seed := s.nextSeed
+	if preset.SeedPolicy == common.SeedPolicyRandom {
+		seed = rand.Intn(math.MaxInt32)
+	}
+
 	imageRequest := services.ImageRequest{
-		WorkflowName:  "default_api.json",
-		ContentPrompt: prompt,
-		Seed:          42,
-		Steps:         20,
-		Size:          512,
-		Ratio:         services.ImageRatioLandscape,
+		WorkflowName:   preset.Workflow,
+		ContentPrompt:  prompt,
+		NegativePrompt: preset.NegativePrompt,
+		Seed:           seed,
+		Steps:          preset.Steps,
+		Size:           preset.Size,
+		Ratio:          services.ImageRatio(preset.Ratio),
+		Sampler:        preset.Sampler,
 	}
 
 	log.WithFields(log.Fields{
+		"preset":         s.activePreset,
 		"workflow":       imageRequest.WorkflowName,
 		"content_prompt": imageRequest.ContentPrompt,
 		"seed":           imageRequest.Seed,
 		"steps":          imageRequest.Steps,
 		"ratio":          imageRequest.Ratio,
-	}).Info("Starting async image generation")
+	}).Info("Enqueuing image generation job")
+
+	job := s.jobs.Enqueue(imageRequest)
+	s.activeJob = job
+	s.selectedJob = job
+	s.lastActiveStatus = job.Status
+	s.dirty = true
+	s.deps.Frames.Request()
+}
 
-	// Start async generation
-	s.resultChannel = s.deps.ComfyUI.AsyncNewImageFromPrompt(imageRequest)
-	s.isGenerating = true
-	s.currentPrompt = prompt
+// cancelActiveJob cancels activeJob via the queue if it's still
+// pending or running; a job that already finished is left alone.
+func (s *ComfyUIScene) cancelActiveJob() {
+	if s.activeJob == nil || (s.activeJob.Status != services.JobPending && s.activeJob.Status != services.JobRunning) {
+		return
+	}
+	if err := s.jobs.Cancel(s.activeJob.ID); err != nil {
+		log.WithError(err).WithField("job_id", s.activeJob.ID).Warn("Failed to cancel job")
+	}
 }
 
-// checkImageGenerationResult checks for async image generation results
-func (s *ComfyUIScene) checkImageGenerationResult() {
-	if !s.isGenerating || s.resultChannel == nil {
+// reuseSelectedJob copies the selected gallery job's prompt and seed back
+// into the input box, ready to tweak and resubmit.
+func (s *ComfyUIScene) reuseSelectedJob() {
+	if s.selectedJob == nil {
 		return
 	}
+	s.input.SetText(s.selectedJob.Request.ContentPrompt)
+	s.nextSeed = s.selectedJob.Request.Seed
+	s.input.Activate()
+	log.WithField("job_id", s.selectedJob.ID).Debug("Reused prompt/seed from selected job")
+}
 
-	// Non-blocking check for results
-	select {
-	case result := <-s.resultChannel:
-		s.handleImageGenerationResult(result)
-	default:
-		// No result yet, continue loading
+// currentPreset returns Config.Comfy.Presets[s.activePreset], falling back
+// to defaultWorkflowPreset if that name is unset or no longer exists (e.g.
+// a config hot-reload removed it).
+func (s *ComfyUIScene) currentPreset() common.WorkflowPreset {
+	if s.deps != nil && s.deps.Config != nil {
+		if preset, ok := s.deps.Config.Comfy.Presets[s.activePreset]; ok {
+			return preset
+		}
 	}
+	return defaultWorkflowPreset
 }
 
-// handleImageGenerationResult processes the async image generation result
-func (s *ComfyUIScene) handleImageGenerationResult(result *services.AsyncImageResult) {
-	s.isGenerating = false
-	s.resultChannel = nil
+// setActivePreset switches to the named preset and resets nextSeed to its
+// SeedPolicyFixed seed, so a freshly-selected preset doesn't keep whatever
+// seed the previous one left behind.
+func (s *ComfyUIScene) setActivePreset(name string) {
+	s.activePreset = name
+	s.nextSeed = s.currentPreset().Seed
+	s.dirty = true
+	s.deps.Frames.Request()
+}
 
-	if result.Error != nil {
-		log.WithError(result.Error).Error("Image generation failed")
-		// Keep placeholder, maybe show error state
+// cyclePreset advances to the next entry in presetNames, wrapping around.
+// It's a no-op with fewer than two presets configured.
+func (s *ComfyUIScene) cyclePreset() {
+	if len(s.presetNames) < 2 {
 		return
 	}
 
-	if result.Result != nil && result.Result.Image != nil {
-		// Replace old image if exists
-		if s.generatedImage != nil {
-			s.generatedImage.Deallocate()
+	next := 0
+	for i, name := range s.presetNames {
+		if name == s.activePreset {
+			next = (i + 1) % len(s.presetNames)
+			break
 		}
+	}
+	s.setActivePreset(s.presetNames[next])
+	log.WithField("preset", s.activePreset).Debug("Switched ComfyUI workflow preset")
+}
 
-		s.generatedImage = result.Result.Image
-		bounds := s.generatedImage.Bounds()
+// presetSelectorBounds returns the on-screen box for the preset dropdown in
+// the top-right corner, shared by handlePresetInput's hit test and
+// drawPresetSelector.
+func (s *ComfyUIScene) presetSelectorBounds(screenWidth int) (x, y, w, h int) {
+	return screenWidth - presetBoxWidth - presetBoxMargin, presetBoxMargin, presetBoxWidth, presetBoxHeight
+}
 
-		log.WithFields(log.Fields{
-			"image_width":  bounds.Dx(),
-			"image_height": bounds.Dy(),
-			"prompt":       s.currentPrompt,
-		}).Info("Image generation completed successfully")
-	} else {
-		log.Error("Received nil image result")
+// handlePresetInput cycles the active preset when the selector box is
+// clicked. CursorPosition is reported in the same logical coordinates as
+// Config.Render.Window.Width/Height (Game.Layout), which is what
+// presetSelectorBounds is computed against in Draw.
+func (s *ComfyUIScene) handlePresetInput() {
+	if len(s.presetNames) < 2 || !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return
+	}
+
+	x, y, w, h := s.presetSelectorBounds(s.deps.Config.Render.Window.Width)
+	cx, cy := ebiten.CursorPosition()
+	if cx >= x && cx < x+w && cy >= y && cy < y+h {
+		s.cyclePreset()
+	}
+}
+
+// doneJobs returns every job with a decoded image available to thumbnail,
+// oldest first - the same order JobQueue.Jobs() reports them in.
+func (s *ComfyUIScene) doneJobs() []*services.Job {
+	all := s.jobs.Jobs()
+	done := make([]*services.Job, 0, len(all))
+	for _, job := range all {
+		if job.Status == services.JobDone && job.Image != nil {
+			done = append(done, job)
+		}
+	}
+	return done
+}
+
+// selectedImage returns the currently selected job's image, or nil if
+// nothing is selected yet.
+func (s *ComfyUIScene) selectedImage() *ebiten.Image {
+	if s.selectedJob == nil {
+		return nil
 	}
+	return s.selectedJob.Image
+}
+
+// isGenerating reports whether activeJob is still pending or running.
+func (s *ComfyUIScene) isGenerating() bool {
+	return s.activeJob != nil && (s.activeJob.Status == services.JobPending || s.activeJob.Status == services.JobRunning)
+}
+
+// imageViewportBounds is the screen-space box the generated/selected image
+// (or its placeholder) draws into, shared by drawGeneratedImage and the
+// camera input/sync helpers below so hit-testing always matches what's on
+// screen.
+func (s *ComfyUIScene) imageViewportBounds(screenWidth, screenHeight int) (x, y, w, h float32) {
+	w = float32(screenWidth) * 0.8
+	if w > imageViewportMaxWidth {
+		w = imageViewportMaxWidth
+	}
+	h = float32(screenHeight) * 0.55
+	if h > imageViewportMaxHeight {
+		h = imageViewportMaxHeight
+	}
+
+	centerX := float32(screenWidth) / 2
+	centerY := float32(screenHeight) / 2
+	x = centerX - w/2
+	y = centerY - h/2 - float32(screenHeight)*0.1
+	return x, y, w, h
+}
+
+// fitZoom returns the zoom level that shows an imgW x imgH image entirely
+// within a viewportW x viewportH viewport.
+func fitZoom(imgW, imgH, viewportW, viewportH float64) float64 {
+	if imgW <= 0 || imgH <= 0 || viewportW <= 0 || viewportH <= 0 {
+		return 1
+	}
+	zoomX := viewportW / imgW
+	zoomY := viewportH / imgH
+	if zoomX < zoomY {
+		return zoomX
+	}
+	return zoomY
+}
+
+// syncImageCamera keeps imageCamera sized to the current viewport box and
+// re-fits it - centered, zoomed to show the whole image - whenever the
+// selected job changes, so switching images in the gallery doesn't leave
+// an old pan/zoom applied to the new one.
+func (s *ComfyUIScene) syncImageCamera(screenWidth, screenHeight int) {
+	_, _, w, h := s.imageViewportBounds(screenWidth, screenHeight)
+	s.imageCamera.SetViewport(float64(w), float64(h))
+
+	image := s.selectedImage()
+	if image == nil {
+		return
+	}
+
+	jobID := ""
+	if s.selectedJob != nil {
+		jobID = s.selectedJob.ID
+	}
+	if jobID == s.cameraImageID {
+		s.imageCamera.Update()
+		return
+	}
+
+	bounds := image.Bounds()
+	imgW, imgH := float64(bounds.Dx()), float64(bounds.Dy())
+
+	s.cameraImageID = jobID
+	s.cameraFitZoom = fitZoom(imgW, imgH, float64(w), float64(h))
+	s.imageCamera.Zoom = s.cameraFitZoom
+	s.imageCamera.Position = camera.Vector{X: imgW / 2, Y: imgH / 2}
+	s.imageCamera.Bounds = &camera.Bounds{Max: camera.Vector{X: imgW, Y: imgH}}
+	s.imageCamera.Update()
+}
+
+// handleImageCameraInput lets the user scroll-wheel zoom and middle-mouse
+// drag to pan the selected image within its viewport box.
+func (s *ComfyUIScene) handleImageCameraInput(screenWidth, screenHeight int) {
+	if s.selectedImage() == nil {
+		return
+	}
+
+	x, y, w, h := s.imageViewportBounds(screenWidth, screenHeight)
+	cx, cy := ebiten.CursorPosition()
+	overViewport := float32(cx) >= x && float32(cx) < x+w && float32(cy) >= y && float32(cy) < y+h
+
+	if _, wheelY := ebiten.Wheel(); wheelY != 0 && overViewport {
+		zoom := s.imageCamera.Zoom * math.Pow(1.1, wheelY)
+		minZoom := s.cameraFitZoom * imageViewportMinZoom
+		maxZoom := s.cameraFitZoom * imageViewportMaxZoom
+		if zoom < minZoom {
+			zoom = minZoom
+		} else if zoom > maxZoom {
+			zoom = maxZoom
+		}
+		s.imageCamera.Zoom = zoom
+		s.dirty = true
+		s.deps.Frames.Request()
+	}
+
+	switch {
+	case inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonMiddle) && overViewport:
+		s.cameraDragging = true
+		s.cameraDragLastX, s.cameraDragLastY = cx, cy
+	case inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonMiddle):
+		s.cameraDragging = false
+	}
+
+	if !s.cameraDragging {
+		return
+	}
+	dx, dy := cx-s.cameraDragLastX, cy-s.cameraDragLastY
+	if dx == 0 && dy == 0 {
+		return
+	}
+	s.imageCamera.Position.X -= float64(dx) / s.imageCamera.Zoom
+	s.imageCamera.Position.Y -= float64(dy) / s.imageCamera.Zoom
+	s.cameraDragLastX, s.cameraDragLastY = cx, cy
+	s.dirty = true
+	s.deps.Frames.Request()
+}
+
+// handleGalleryInput scrolls the thumbnail strip with the mouse wheel and
+// selects a thumbnail on click.
+func (s *ComfyUIScene) handleGalleryInput() {
+	if _, wheelY := ebiten.Wheel(); wheelY != 0 {
+		s.galleryScroll -= int(wheelY * 20)
+		if s.galleryScroll < 0 {
+			s.galleryScroll = 0
+		}
+		s.dirty = true
+		s.deps.Frames.Request()
+	}
+
+	if !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return
+	}
+
+	cx, cy := ebiten.CursorPosition()
+	if cy < galleryTop || cy > galleryTop+thumbnailSize {
+		return
+	}
+
+	x := thumbnailGap - s.galleryScroll
+	for _, job := range s.doneJobs() {
+		if cx >= x && cx < x+thumbnailSize {
+			s.selectedJob = job
+			s.dirty = true
+			s.deps.Frames.Request()
+			return
+		}
+		x += thumbnailSize + thumbnailGap
+	}
+}
+
+// drawGallery renders a horizontally-scrollable strip of thumbnails for
+// every completed job this session, with the selected one outlined.
+func (s *ComfyUIScene) drawGallery(screen *ebiten.Image, screenWidth int) {
+	done := s.doneJobs()
+	if len(done) == 0 {
+		return
+	}
+
+	stripHeight := float32(thumbnailSize + thumbnailGap*2)
+	vector.DrawFilledRect(screen, 0, float32(galleryTop)-float32(thumbnailGap), float32(screenWidth), stripHeight, color.RGBA{20, 20, 28, 180}, false)
+
+	x := thumbnailGap - s.galleryScroll
+	for _, job := range done {
+		if x+thumbnailSize >= 0 && x <= screenWidth {
+			bounds := job.Image.Bounds()
+			scale := float64(thumbnailSize) / math.Max(float64(bounds.Dx()), float64(bounds.Dy()))
+
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Scale(scale, scale)
+			op.GeoM.Translate(float64(x), float64(galleryTop))
+			screen.DrawImage(job.Image, op)
+
+			if s.selectedJob == job {
+				vector.StrokeRect(screen, float32(x), float32(galleryTop), float32(thumbnailSize), float32(thumbnailSize), 2, s.theme.accent, false)
+			}
+		}
+		x += thumbnailSize + thumbnailGap
+	}
+}
+
+// drawPresetSelector renders the active workflow preset's name in the
+// top-right corner as a small clickable box; see handlePresetInput.
+func (s *ComfyUIScene) drawPresetSelector(screen *ebiten.Image, screenWidth int) {
+	if len(s.presetNames) == 0 {
+		return
+	}
+
+	x, y, w, h := s.presetSelectorBounds(screenWidth)
+	vector.DrawFilledRect(screen, float32(x), float32(y), float32(w), float32(h), s.theme.inputBox, false)
+	vector.StrokeRect(screen, float32(x), float32(y), float32(w), float32(h), 2, s.theme.border, false)
+
+	label := fmt.Sprintf("Preset: %s", s.activePreset)
+	if len(s.presetNames) > 1 {
+		label += " (Tab to switch)"
+	}
+	ebitenutil.DebugPrintAt(screen, label, x+8, y+6)
 }
 
 // drawAnimatedBackground renders a cool animated background with gradients and geometric shapes
@@ -235,19 +763,22 @@ func (s *ComfyUIScene) drawAnimatedBackground(screen *ebiten.Image) {
 	s.drawParticleEffect(screen, screenWidth, screenHeight)
 }
 
-// drawGradientBackground creates an animated gradient background
+// drawGradientBackground creates an animated gradient background, drifting
+// around the theme's configured top/bottom stops rather than fixed colors.
 func (s *ComfyUIScene) drawGradientBackground(screen *ebiten.Image, width, height float32) {
 	// Create animated color values
 	time := s.animationTime
+	top := s.theme.gradientTop
+	bottom := s.theme.gradientBottom
 
 	// Base colors that shift over time
-	r1 := uint8(20 + 15*math.Sin(time*0.3))
-	g1 := uint8(25 + 20*math.Sin(time*0.4+1))
-	b1 := uint8(40 + 25*math.Sin(time*0.2+2))
+	r1 := uint8(float64(top.R) + 15*math.Sin(time*0.3))
+	g1 := uint8(float64(top.G) + 20*math.Sin(time*0.4+1))
+	b1 := uint8(float64(top.B) + 25*math.Sin(time*0.2+2))
 
-	r2 := uint8(40 + 20*math.Sin(time*0.25+3))
-	g2 := uint8(20 + 15*math.Sin(time*0.35+4))
-	b2 := uint8(60 + 30*math.Sin(time*0.3+5))
+	r2 := uint8(float64(bottom.R) + 20*math.Sin(time*0.25+3))
+	g2 := uint8(float64(bottom.G) + 15*math.Sin(time*0.35+4))
+	b2 := uint8(float64(bottom.B) + 30*math.Sin(time*0.3+5))
 
 	// Draw gradient rectangles from top to bottom
 	steps := 50
@@ -271,7 +802,7 @@ func (s *ComfyUIScene) drawParticleEffect(screen *ebiten.Image, width, height fl
 	time := s.animationTime
 
 	// Draw moving particles
-	for i := 0; i < 50; i++ {
+	for i := 0; i < s.theme.particleCount; i++ {
 		// Use deterministic "random" based on index
 		seedX := float64(i*123%1000) / 1000.0
 		seedY := float64(i*456%1000) / 1000.0
@@ -293,36 +824,18 @@ func (s *ComfyUIScene) drawParticleEffect(screen *ebiten.Image, width, height fl
 
 // drawGeneratedImage draws a placeholder image in the center with shadow
 func (s *ComfyUIScene) drawGeneratedImage(screen *ebiten.Image, screenWidth, screenHeight int) {
-	// Calculate image dimensions (16:9 aspect ratio - LANDSCAPE orientation, larger dimension = 512)
-	// Since 16:9 means width:height = 16:9, width is larger (landscape)
-	baseWidth := float32(512)  // 512 (larger dimension)
-	baseHeight := float32(288) // 512 * 9/16
-
-	// Scale relative to screen size (use 80% of screen width as max)
-	maxWidth := float32(screenWidth) * 0.8
-	scale := maxWidth / baseWidth
-	if scale > 1.0 {
-		scale = 1.0 // Don't scale up beyond original size
-	}
-
-	imageWidth := baseWidth * scale
-	imageHeight := baseHeight * scale
-
-	// Center the image
-	centerX := float32(screenWidth) / 2
-	centerY := float32(screenHeight) / 2
-	imageX := centerX - imageWidth/2
-	imageY := centerY - imageHeight/2 - (float32(screenHeight) * 0.1)
+	imageX, imageY, imageWidth, imageHeight := s.imageViewportBounds(screenWidth, screenHeight)
 
 	// Draw shadow (offset down and right)
-	shadowOffset := float32(8 * scale)
-	shadowX := imageX + shadowOffset
-	shadowY := imageY + shadowOffset
+	shadowOffset := float32(8)
 	shadowColor := color.RGBA{0, 0, 0, 100} // Semi-transparent black
-	vector.DrawFilledRect(screen, shadowX, shadowY, imageWidth, imageHeight, shadowColor, false)
+	vector.DrawFilledRect(screen, imageX+shadowOffset, imageY+shadowOffset, imageWidth, imageHeight, shadowColor, false)
+
+	img := s.selectedImage()
+	generating := s.isGenerating()
 
-	if s.generatedImage != nil {
-		// Draw the real generated image
+	if img != nil {
+		// Draw the real generated image, panned/zoomed via imageCamera
 		s.drawRealImage(screen, imageX, imageY, imageWidth, imageHeight)
 	} else {
 		// Draw placeholder background
@@ -333,7 +846,7 @@ func (s *ComfyUIScene) drawGeneratedImage(screen *ebiten.Image, screenWidth, scr
 		borderColor := color.RGBA{100, 100, 120, 255}
 		vector.StrokeRect(screen, imageX, imageY, imageWidth, imageHeight, 2, borderColor, false)
 
-		if s.isGenerating {
+		if generating {
 			// Draw loading animation
 			s.drawLoadingAnimation(screen, imageX, imageY, imageWidth, imageHeight)
 		} else {
@@ -344,15 +857,16 @@ func (s *ComfyUIScene) drawGeneratedImage(screen *ebiten.Image, screenWidth, scr
 
 	// Draw image info text
 	var infoText string
-	if s.isGenerating {
-		infoText = "Generating image... Please wait"
-	} else if s.generatedImage != nil {
-		infoText = fmt.Sprintf("Generated: \"%s\"", s.currentPrompt)
-		if len(infoText) > 50 {
-			infoText = fmt.Sprintf("Generated: \"%.45s...\"", s.currentPrompt)
+	if generating {
+		infoText = "Generating image... Press Delete to cancel"
+	} else if img != nil {
+		prompt := s.selectedJob.Request.ContentPrompt
+		infoText = fmt.Sprintf("Generated: \"%s\" (scroll to zoom, middle-drag to pan)", prompt)
+		if len(infoText) > 70 {
+			infoText = fmt.Sprintf("Generated: \"%.25s...\" (scroll to zoom, middle-drag to pan)", prompt)
 		}
 	} else {
-		infoText = "Generated Image Preview (Landscape 16:9)"
+		infoText = "Generated Image Preview"
 	}
 
 	infoY := imageY + imageHeight + 20
@@ -364,38 +878,26 @@ func (s *ComfyUIScene) drawGeneratedImage(screen *ebiten.Image, screenWidth, scr
 	ebitenutil.DebugPrintAt(screen, infoText, int(imageX+textOffsetX), int(infoY))
 }
 
-// drawRealImage draws the actual generated image scaled to fit the placeholder area
+// drawRealImage draws the selected image through imageCamera, clipped to
+// the viewport box at (x, y, width, height) so a zoomed-in image doesn't
+// spill outside its frame.
 func (s *ComfyUIScene) drawRealImage(screen *ebiten.Image, x, y, width, height float32) {
-	if s.generatedImage == nil {
+	img := s.selectedImage()
+	if img == nil {
 		return
 	}
 
-	// Draw the generated image with proper scaling
 	options := &ebiten.DrawImageOptions{}
+	options.GeoM = s.imageCamera.GeoM()
+	options.GeoM.Translate(float64(x), float64(y))
 
-	// Calculate scaling to fit within the placeholder area
-	imgBounds := s.generatedImage.Bounds()
-	scaleX := width / float32(imgBounds.Dx())
-	scaleY := height / float32(imgBounds.Dy())
-
-	// Use the smaller scale to maintain aspect ratio
-	scale := scaleX
-	if scaleY < scaleX {
-		scale = scaleY
+	viewportRect := image.Rect(int(x), int(y), int(x+width), int(y+height))
+	viewport, ok := screen.SubImage(viewportRect).(*ebiten.Image)
+	if !ok {
+		screen.DrawImage(img, options)
+		return
 	}
-
-	// Scale and position the image
-	options.GeoM.Scale(float64(scale), float64(scale))
-
-	// Center the scaled image within the placeholder area
-	scaledWidth := float32(imgBounds.Dx()) * scale
-	scaledHeight := float32(imgBounds.Dy()) * scale
-	offsetX := (width - scaledWidth) / 2
-	offsetY := (height - scaledHeight) / 2
-
-	options.GeoM.Translate(float64(x+offsetX), float64(y+offsetY))
-
-	screen.DrawImage(s.generatedImage, options)
+	viewport.DrawImage(img, options)
 }
 
 // drawLoadingAnimation draws a spinning loading animation
@@ -482,42 +984,46 @@ func (s *ComfyUIScene) drawPlaceholderContent(screen *ebiten.Image, x, y, width,
 
 // drawTextInput renders the text input box and related UI elements at the bottom of the screen
 func (s *ComfyUIScene) drawTextInput(screen *ebiten.Image, screenWidth, screenHeight int) {
-	// Draw text input box at the bottom
-	inputBoxHeight := 40
+	// Draw text input box at the bottom, tall enough for however many
+	// lines the prompt has wrapped to.
+	inputBoxHeight := s.input.Height() + 24
 	inputBoxY := screenHeight - inputBoxHeight - 20
 	inputBoxWidth := screenWidth - 40
 	inputBoxX := 20
 
 	// Draw input box background
-	inputBgColor := color.RGBA{40, 40, 50, 200}
-	if s.inputActive {
-		inputBgColor = color.RGBA{50, 50, 70, 220}
+	inputBgColor := s.theme.inputBox
+	if s.input.Active() {
+		inputBgColor = brighten(inputBgColor, 10, 20)
 	}
 	vector.DrawFilledRect(screen, float32(inputBoxX), float32(inputBoxY), float32(inputBoxWidth), float32(inputBoxHeight), inputBgColor, false)
 
 	// Draw input box border
-	borderColor := color.RGBA{80, 80, 100, 255}
-	if s.inputActive {
-		borderColor = color.RGBA{100, 150, 200, 255}
+	borderColor := s.theme.border
+	if s.input.Active() {
+		borderColor = s.theme.accent
 	}
 	vector.StrokeRect(screen, float32(inputBoxX), float32(inputBoxY), float32(inputBoxWidth), float32(inputBoxHeight), 2, borderColor, false)
 
-	// Display text with cursor if active
-	displayText := s.textInput
-	if s.inputActive && s.cursorVisible {
-		displayText += "|"
-	}
-
-	ebitenutil.DebugPrintAt(screen, displayText, inputBoxX+10, inputBoxY+12)
+	s.input.Draw(screen, inputBoxX+10, inputBoxY+12)
 
 	// Draw status information
 	statusY := inputBoxY - 40
 	var statusText string
 
-	if s.isGenerating {
-		statusText = "Generating image... Please wait"
-	} else if s.inputActive {
-		statusText = "Type your prompt, press Enter to generate image"
+	if s.isGenerating() {
+		statusText = "Generating image... Please wait (Delete to cancel)"
+		if p := s.activeJob.Progress; p != nil {
+			if p.Cached {
+				statusText = p.Message + " (Delete to cancel)"
+			} else if p.StepMax > 0 {
+				statusText = fmt.Sprintf("Sampling: %s (%d/%d) (Delete to cancel)", p.NodeTitle, p.StepValue, p.StepMax)
+			} else if p.NodeTitle != "" {
+				statusText = fmt.Sprintf("Running: %s (Delete to cancel)", p.NodeTitle)
+			}
+		}
+	} else if s.input.Active() {
+		statusText = "Type your prompt, Enter to generate, Shift+Enter for a new line"
 	} else {
 		statusText = "Press Enter to activate text input and generate images"
 	}
@@ -537,8 +1043,23 @@ func (s *ComfyUIScene) Draw(screen *ebiten.Image) {
 	// Draw main content text
 	ebitenutil.DebugPrintAt(screen, "ComfyUI Image Generation", 20, 20)
 
+	// Draw the workflow preset selector
+	s.drawPresetSelector(screen, screenWidth)
+
+	// Draw the scrollable history gallery
+	s.drawGallery(screen, screenWidth)
+
 	// Draw text input interface
 	s.drawTextInput(screen, screenWidth, screenHeight)
+
+	s.dirty = false
+}
+
+// Dirty reports whether the scene has changed since its last Draw; see the
+// frameCount tick in Update and the input/result handlers above for what
+// sets it.
+func (s *ComfyUIScene) Dirty() bool {
+	return s.dirty
 }
 
 func (s *ComfyUIScene) FirstLoad() {
@@ -554,10 +1075,10 @@ func (s *ComfyUIScene) OnEnter() {
 	log.Info("Entering ComfyUI scene")
 
 	// Reset text input state
-	s.inputActive = false
-	s.cursorVisible = true
-	s.lastCursorBlink = time.Now()
+	s.input.Deactivate()
 	s.animationTime = 0.0
+	s.dirty = true
+	s.deps.Frames.Request()
 
 	log.Debug("ComfyUI scene entered (background image functionality disabled)")
 }
@@ -565,19 +1086,12 @@ func (s *ComfyUIScene) OnEnter() {
 func (s *ComfyUIScene) OnExit() {
 	log.Info("Exiting ComfyUI scene")
 
-	// Clean up generated image
-	if s.generatedImage != nil {
-		log.Debug("Deallocating generated image")
-		s.generatedImage.Deallocate()
-		s.generatedImage = nil
-	}
-
-	// Reset generation state
-	s.isGenerating = false
-	s.resultChannel = nil
-	s.currentPrompt = ""
+	// Job history (and its images) outlives the scene - that's the point
+	// of the gallery - so there's nothing to tear down here beyond the
+	// input widget.
+	s.input.Deactivate()
 
-	log.Debug("ComfyUI scene cleanup completed (background image functionality disabled)")
+	log.Debug("ComfyUI scene cleanup completed")
 }
 
 func (s *ComfyUIScene) IsLoaded() bool {
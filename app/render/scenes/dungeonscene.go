@@ -0,0 +1,342 @@
+// In DungeonScene, press Q to return to the StartScene.
+package scenes
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	log "github.com/sirupsen/logrus"
+
+	"app/render/scenes/background"
+	"app/render/scenes/camera"
+	"app/tilemap"
+)
+
+const (
+	// defaultDungeonMapPath is used when common.Config.Dungeon.MapPath is
+	// unset.
+	defaultDungeonMapPath = "assets/tilesets/dungeon_sample.tmx"
+	dungeonParallaxImage  = "assets/images/dungeon_parallax.png"
+
+	explorerSpeed = 2.0  // pixels per tick
+	explorerSize  = 12.0 // pixels, both width and height
+)
+
+func init() {
+	RegisterScene(SceneDescriptor{
+		ID:   DungeonSceneId,
+		Name: "Dungeon Demo",
+		Factory: func(deps *Deps) Scene {
+			return NewDungeonScene(deps)
+		},
+		ShowInMenu: true,
+		MenuOrder:  5,
+	})
+}
+
+// rect is an axis-aligned world-space rectangle, used for the wall and
+// hazard zones read from the map's object layers.
+type rect struct {
+	X, Y, W, H float64
+}
+
+func (r rect) intersects(o rect) bool {
+	return r.X < o.X+o.W && r.X+r.W > o.X && r.Y < o.Y+o.H && r.Y+r.H > o.Y
+}
+
+// parallaxPlane is one depth-scrolled background layer behind the
+// dungeon's tile layers. Its BackgroundLayer offset is pinned every frame
+// from the camera's position (see Draw), rather than accumulated from a
+// constant Velocity like StartScene's ambient background - so distant
+// planes visibly lag a panning camera instead of drifting on their own.
+type parallaxPlane struct {
+	layer *background.BackgroundLayer
+	depth float64 // 0 = sticks to the screen, 1 = scrolls 1:1 with the world
+}
+
+// DungeonScene demonstrates the app/tilemap loader: it renders a .tmx
+// map's tile layers through a pannable/zoomable Camera, with a
+// parallax-scrolled background behind them, and turns the map's object
+// layers into collidable walls and a hazard zone that block/flag a
+// keyboard-controlled explorer.
+type DungeonScene struct {
+	BaseScene
+	loaded bool
+	deps   *Deps
+
+	mapData    *tilemap.Map
+	tileImages map[int]*ebiten.Image
+	walls      []rect
+	hazards    []rect
+
+	parallax []parallaxPlane
+	cam      *camera.Camera
+	explorer camera.Vector
+}
+
+func NewDungeonScene(deps *Deps) *DungeonScene {
+	width := float64(deps.Config.Render.Window.Width)
+	height := float64(deps.Config.Render.Window.Height)
+
+	s := &DungeonScene{
+		deps: deps,
+		cam:  camera.New(width, height),
+	}
+	s.cam.Zoom = 2.5
+	s.cam.Smoothing = 0.08
+	s.cam.Target = &s.explorer
+
+	s.loadParallax()
+	s.loadMap()
+
+	return s
+}
+
+func (s *DungeonScene) GetName() string {
+	return "Dungeon Demo"
+}
+
+// loadParallax builds the 4 depth planes drawn behind the tile layers,
+// all tiling the same ambient texture at different depths/alphas since
+// this demo has no distinct art for each plane.
+func (s *DungeonScene) loadParallax() {
+	tile, err := s.deps.Assets.LoadImage(dungeonParallaxImage)
+	if err != nil {
+		log.WithError(err).Warn("Failed to load dungeon parallax texture, background will be blank")
+		return
+	}
+
+	depths := []float64{0.1, 0.3, 0.55, 0.8}
+	alphas := []float64{0.25, 0.35, 0.5, 0.7}
+	for i, depth := range depths {
+		s.parallax = append(s.parallax, parallaxPlane{
+			layer: background.NewBackgroundLayer(tile, background.Velocity{}, alphas[i]),
+			depth: depth,
+		})
+	}
+}
+
+// loadMap parses Config.Dungeon.MapPath (or defaultDungeonMapPath),
+// builds a sub-image per tile GID, and splits its object layers into
+// walls/hazards/the explorer's spawn point. A load failure is logged and
+// left as a Warn - the scene still runs, just with an empty map.
+func (s *DungeonScene) loadMap() {
+	path := s.deps.Config.Dungeon.MapPath
+	if path == "" {
+		path = defaultDungeonMapPath
+	}
+
+	m, err := tilemap.LoadMap(path)
+	if err != nil {
+		log.WithError(err).WithField("path", path).Warn("Failed to load dungeon map")
+		return
+	}
+	s.mapData = m
+
+	tilesetImages := make(map[string]*ebiten.Image)
+	s.tileImages = make(map[int]*ebiten.Image)
+	for _, ts := range m.Tilesets {
+		img, ok := tilesetImages[ts.ImageSource]
+		if !ok {
+			loaded, err := s.deps.Assets.LoadImage(ts.ImageSource)
+			if err != nil {
+				log.WithError(err).WithField("path", ts.ImageSource).Warn("Failed to load tileset image")
+				continue
+			}
+			img = loaded
+			tilesetImages[ts.ImageSource] = img
+		}
+		if ts.Columns <= 0 {
+			continue
+		}
+		for local := 0; local < ts.TileCount; local++ {
+			col := local % ts.Columns
+			row := local / ts.Columns
+			x0 := col * ts.TileWidth
+			y0 := row * ts.TileHeight
+			sub := img.SubImage(image.Rect(x0, y0, x0+ts.TileWidth, y0+ts.TileHeight)).(*ebiten.Image)
+			s.tileImages[ts.FirstGID+local] = sub
+		}
+	}
+
+	for _, group := range m.ObjectGroups {
+		for _, obj := range group.Objects {
+			r := rect{X: obj.X, Y: obj.Y, W: obj.Width, H: obj.Height}
+			switch group.Name {
+			case "collision":
+				s.walls = append(s.walls, r)
+			case "hazards":
+				s.hazards = append(s.hazards, r)
+			case "spawns":
+				s.explorer = camera.Vector{X: obj.X, Y: obj.Y}
+			}
+		}
+	}
+
+	s.cam.Bounds = &camera.Bounds{
+		Min: camera.Vector{X: 0, Y: 0},
+		Max: camera.Vector{X: m.PixelWidth(), Y: m.PixelHeight()},
+	}
+}
+
+func (s *DungeonScene) Draw(screen *ebiten.Image) {
+	width := float64(s.deps.Config.Render.Window.Width)
+	height := float64(s.deps.Config.Render.Window.Height)
+	s.cam.SetViewport(width, height)
+
+	for i := range s.parallax {
+		plane := &s.parallax[i]
+		plane.layer.SetOffset(s.cam.Position.X*plane.depth, s.cam.Position.Y*plane.depth)
+		plane.layer.Draw(screen)
+	}
+
+	s.drawTileLayers(screen)
+	s.drawHazards(screen)
+	s.drawExplorer(screen)
+
+	ebitenutil.DebugPrintAt(screen, "Dungeon Demo (Q: menu, WASD/Arrows: move, Scroll: zoom)", 40, 40)
+	if s.inHazard() {
+		ebitenutil.DebugPrintAt(screen, "HAZARD!", int(width)/2-24, 60)
+	}
+
+	fps := ebiten.ActualFPS()
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("FPS: %0.1f", fps), 10, 10)
+}
+
+// drawTileLayers draws every tile of every layer via DrawImage with the
+// pre-cut sub-image for that tile's GID (see loadMap), projected through
+// the camera.
+func (s *DungeonScene) drawTileLayers(screen *ebiten.Image) {
+	if s.mapData == nil {
+		return
+	}
+	tw := float64(s.mapData.TileWidth)
+	th := float64(s.mapData.TileHeight)
+	base := s.cam.GeoM()
+	for _, layer := range s.mapData.Layers {
+		for ty := 0; ty < layer.Height; ty++ {
+			for tx := 0; tx < layer.Width; tx++ {
+				gid := layer.GIDAt(tx, ty)
+				if gid == 0 {
+					continue
+				}
+				img, ok := s.tileImages[gid]
+				if !ok {
+					continue
+				}
+				op := &ebiten.DrawImageOptions{}
+				op.GeoM.Translate(float64(tx)*tw, float64(ty)*th)
+				op.GeoM.Concat(base)
+				screen.DrawImage(img, op)
+			}
+		}
+	}
+}
+
+func (s *DungeonScene) drawHazards(screen *ebiten.Image) {
+	for _, h := range s.hazards {
+		topLeft := s.cam.WorldToScreen(camera.Vector{X: h.X, Y: h.Y})
+		bottomRight := s.cam.WorldToScreen(camera.Vector{X: h.X + h.W, Y: h.Y + h.H})
+		vector.DrawFilledRect(screen,
+			float32(topLeft.X), float32(topLeft.Y),
+			float32(bottomRight.X-topLeft.X), float32(bottomRight.Y-topLeft.Y),
+			color.RGBA{200, 30, 30, 90}, false)
+	}
+}
+
+func (s *DungeonScene) drawExplorer(screen *ebiten.Image) {
+	pos := s.cam.WorldToScreen(s.explorer)
+	col := color.RGBA{80, 200, 255, 255}
+	if s.inHazard() {
+		col = color.RGBA{255, 60, 60, 255}
+	}
+	vector.DrawFilledCircle(screen, float32(pos.X), float32(pos.Y), float32(explorerSize/2*s.cam.Zoom), col, false)
+}
+
+func (s *DungeonScene) FirstLoad()     { s.loaded = true }
+func (s *DungeonScene) IsLoaded() bool { return s.loaded }
+func (s *DungeonScene) OnEnter()       {}
+func (s *DungeonScene) OnExit()        {}
+
+func (s *DungeonScene) Update() SceneTransition {
+	if ebiten.IsKeyPressed(ebiten.KeyQ) {
+		return NextScene(StartSceneId, Transition{Kind: TransitionFade, Duration: 400 * time.Millisecond})
+	}
+
+	dx, dy := 0.0, 0.0
+	if ebiten.IsKeyPressed(ebiten.KeyLeft) || ebiten.IsKeyPressed(ebiten.KeyA) {
+		dx -= explorerSpeed
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyRight) || ebiten.IsKeyPressed(ebiten.KeyD) {
+		dx += explorerSpeed
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyUp) || ebiten.IsKeyPressed(ebiten.KeyW) {
+		dy -= explorerSpeed
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyDown) || ebiten.IsKeyPressed(ebiten.KeyS) {
+		dy += explorerSpeed
+	}
+	if dx != 0 || dy != 0 {
+		s.tryMove(dx, dy)
+	}
+
+	if _, scrollY := ebiten.Wheel(); scrollY != 0 {
+		factor := 1.0 + 0.1*scrollY
+		if s.cam.Zoom*factor > 0.5 && s.cam.Zoom*factor < 6 {
+			s.cam.Zoom *= factor
+		}
+	}
+
+	s.cam.Update()
+
+	return NextScene(DungeonSceneId, Transition{})
+}
+
+// tryMove moves the explorer by (dx, dy), resolving each axis separately
+// against s.walls so sliding along a wall in one direction still works
+// even if the other direction is blocked.
+func (s *DungeonScene) tryMove(dx, dy float64) {
+	next := s.explorer
+	next.X += dx
+	if !s.collidesWalls(next) {
+		s.explorer.X = next.X
+	}
+
+	next = s.explorer
+	next.Y += dy
+	if !s.collidesWalls(next) {
+		s.explorer.Y = next.Y
+	}
+}
+
+func (s *DungeonScene) collidesWalls(pos camera.Vector) bool {
+	r := explorerRect(pos)
+	for _, wall := range s.walls {
+		if r.intersects(wall) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *DungeonScene) inHazard() bool {
+	r := explorerRect(s.explorer)
+	for _, hazard := range s.hazards {
+		if r.intersects(hazard) {
+			return true
+		}
+	}
+	return false
+}
+
+func explorerRect(pos camera.Vector) rect {
+	half := explorerSize / 2
+	return rect{X: pos.X - half, Y: pos.Y - half, W: explorerSize, H: explorerSize}
+}
+
+var _ Scene = (*DungeonScene)(nil)
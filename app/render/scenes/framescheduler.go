@@ -0,0 +1,90 @@
+package scenes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// FrameScheduler coalesces redraw requests into calls to
+// ebiten.ScheduleFrame(), so that a burst of input (e.g. holding a key,
+// dragging the mouse) can't schedule more frames than the display can
+// actually show. Requests arriving within MinInterval of the last
+// scheduled frame are merged into a single trailing call.
+type FrameScheduler struct {
+	minInterval time.Duration
+
+	mu       sync.Mutex
+	lastSent time.Time
+	timer    *time.Timer
+	pending  bool
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewFrameScheduler creates a scheduler that allows at most one
+// ebiten.ScheduleFrame() call per minInterval (e.g. time.Second/144).
+func NewFrameScheduler(minInterval time.Duration) *FrameScheduler {
+	return &FrameScheduler{
+		minInterval: minInterval,
+		stop:        make(chan struct{}),
+	}
+}
+
+// Request asks for another frame to be drawn. If the last scheduled frame
+// was less than minInterval ago, the request is coalesced with any other
+// pending request and fires once the interval elapses.
+func (f *FrameScheduler) Request() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	wait := f.minInterval - time.Since(f.lastSent)
+	if wait <= 0 {
+		f.schedule()
+		return
+	}
+
+	if f.pending {
+		// A coalesced request is already in flight; drop this one.
+		return
+	}
+	f.pending = true
+	f.timer = time.AfterFunc(wait, f.fireCoalesced)
+}
+
+func (f *FrameScheduler) fireCoalesced() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	select {
+	case <-f.stop:
+		return
+	default:
+	}
+
+	f.pending = false
+	f.schedule()
+}
+
+// schedule calls ebiten.ScheduleFrame and records when it did so. Callers
+// must hold f.mu.
+func (f *FrameScheduler) schedule() {
+	f.lastSent = time.Now()
+	ebiten.ScheduleFrame()
+}
+
+// Stop cancels any pending coalesced request. The scheduler is unusable
+// afterwards.
+func (f *FrameScheduler) Stop() {
+	f.stopOnce.Do(func() {
+		close(f.stop)
+	})
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.timer != nil {
+		f.timer.Stop()
+	}
+}
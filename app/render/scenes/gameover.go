@@ -6,7 +6,20 @@ import (
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
 
+func init() {
+	RegisterScene(SceneDescriptor{
+		ID:   GameOverSceneId,
+		Name: "Game Over",
+		Factory: func(deps *Deps) Scene {
+			return NewGameOverScene(deps)
+		},
+		ShowInMenu: true,
+		MenuOrder:  4,
+	})
+}
+
 type GameOverScene struct {
+	BaseScene
 	loaded bool
 	deps   *Deps
 	// Add your scene-specific fields here
@@ -23,16 +36,17 @@ func (s *GameOverScene) GetName() string {
 	return "Game Over"
 }
 
-func (s *GameOverScene) Update() SceneId {
+func (s *GameOverScene) Update() SceneTransition {
 	// Handle escape key to exit
 	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
-		return ExitSceneId
+		return NextScene(ExitSceneId, Transition{})
 	}
 
 	// Handle input and update scene logic
-	// Return the SceneId for the next scene or current scene
+	// Return NextScene(id, transition) for the scene to switch to, or
+	// your own scene id to stay here
 
-	return GameOverSceneId
+	return NextScene(GameOverSceneId, Transition{})
 }
 
 func (s *GameOverScene) Draw(screen *ebiten.Image) {
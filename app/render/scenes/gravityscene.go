@@ -5,12 +5,22 @@ import (
 	"image/color"
 	"math"
 	"math/rand"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"app/console"
+	"app/net"
+	"app/physics"
 )
 
+// gravityAABBMargin pads the Barnes-Hut quadtree's world bounds past the
+// bodies it actually contains, so a body sitting exactly on the computed
+// edge still falls strictly inside a quadrant.
+const gravityAABBMargin = 1.0
+
 type TrailPoint struct {
 	X, Y float64
 }
@@ -26,6 +36,7 @@ type GravityBody struct {
 }
 
 type GravityScene struct {
+	BaseScene
 	loaded                 bool
 	deps                   *Deps
 	Bodies                 []GravityBody
@@ -35,6 +46,15 @@ type GravityScene struct {
 	middleDragging         bool
 	gravity                float64
 	stars                  []TrailPoint
+
+	// physEnabled, gravityEnabled, and trailsEnabled are exposed to the
+	// dev console (see Cvars) as phys_enabled/gravity_enabled/
+	// trails_enabled. trailLength replaces the TrailLength constant as
+	// trail.length, so it can be retuned without a rebuild.
+	physEnabled    bool
+	gravityEnabled bool
+	trailsEnabled  bool
+	trailLength    float64
 }
 
 const (
@@ -46,6 +66,18 @@ const (
 	NumStars         = 300 // Increased from 120 for better coverage
 )
 
+func init() {
+	RegisterScene(SceneDescriptor{
+		ID:   GravitySceneId,
+		Name: "Gravity Demo",
+		Factory: func(deps *Deps) Scene {
+			return NewGravityScene(deps)
+		},
+		ShowInMenu: true,
+		MenuOrder:  2,
+	})
+}
+
 func NewGravityScene(deps *Deps) *GravityScene {
 	stars := make([]TrailPoint, NumStars)
 	width := float64(deps.Config.Render.Window.Width)
@@ -57,12 +89,97 @@ func NewGravityScene(deps *Deps) *GravityScene {
 		}
 	}
 	return &GravityScene{
-		loaded:  false,
-		deps:    deps,
-		Bodies:  []GravityBody{},
-		zoom:    1.0,
-		gravity: DefaultG,
-		stars:   stars,
+		loaded:         false,
+		deps:           deps,
+		Bodies:         []GravityBody{},
+		zoom:           1.0,
+		gravity:        DefaultG,
+		stars:          stars,
+		physEnabled:    true,
+		gravityEnabled: true,
+		trailsEnabled:  true,
+		trailLength:    TrailLength,
+	}
+}
+
+func (s *GravityScene) GetName() string {
+	return "Gravity Demo"
+}
+
+// Cvars exposes GravityScene's tunables to the dev console (see
+// app/console). It's called once whenever this scene becomes active, not
+// every frame.
+func (s *GravityScene) Cvars() map[string]*console.Cvar {
+	return map[string]*console.Cvar{
+		"phys_enabled":    console.NewBoolCvar("phys_enabled", &s.physEnabled),
+		"gravity_enabled": console.NewBoolCvar("gravity_enabled", &s.gravityEnabled),
+		"trails_enabled":  console.NewBoolCvar("trails_enabled", &s.trailsEnabled),
+		"gravity.g":       console.NewFloatCvar("gravity.g", &s.gravity),
+		"trail.length":    console.NewFloatCvar("trail.length", &s.trailLength),
+	}
+}
+
+// Spawn adds n random bodies at random positions, for the console's
+// `spawn <n>` command.
+func (s *GravityScene) Spawn(n int) {
+	width := float64(s.deps.Config.Render.Window.Width)
+	height := float64(s.deps.Config.Render.Window.Height)
+	for i := 0; i < n; i++ {
+		fx, fy := s.screenToWorld(rand.Float64()*width, rand.Float64()*height)
+		mass := MinMass + rand.Float64()*(MaxMass-MinMass)
+		s.Bodies = append(s.Bodies, GravityBody{X: fx, Y: fy, Mass: mass, Color: massToColor(mass)})
+	}
+}
+
+// Clear removes every body, for the console's `clear` command.
+func (s *GravityScene) Clear() {
+	s.Bodies = s.Bodies[:0]
+}
+
+// Entities reports the current bodies for the online sandbox (see
+// app/net.Server.Broadcast). A body's slice index doubles as its net.ID,
+// the same way BallsScene.Entities uses its own slice index: GravityScene
+// only ever appends or removes via a full merge-rebuild of the slice (see
+// FixedUpdate), so indices stay stable tick to tick the way net.Source
+// requires. Trail/Flash/IsComet don't cross the wire - they're cosmetic
+// and rebuilt locally by ApplySnapshot.
+func (s *GravityScene) Entities() []net.Entity {
+	entities := make([]net.Entity, len(s.Bodies))
+	for i, b := range s.Bodies {
+		r, g, bl, _ := b.Color.RGBA()
+		entities[i] = net.Entity{
+			ID:     uint32(i),
+			X:      float32(b.X),
+			Y:      float32(b.Y),
+			VX:     float32(b.VX),
+			VY:     float32(b.VY),
+			Radius: float32(massToRadius(b.Mass)),
+			R:      uint8(r >> 8),
+			G:      uint8(g >> 8),
+			B:      uint8(bl >> 8),
+		}
+	}
+	return entities
+}
+
+// ApplySnapshot replaces the local bodies with the online sandbox host's
+// latest broadcast state (see app/net.Client.Entities), for a joining
+// client - the host is authoritative, so this is a wholesale replace, not
+// a merge. Mass isn't sent over the wire (see Entities), so it's
+// recovered from Radius via radiusToMass - close enough for Draw's label
+// and bodyAt's hit-testing, the only things a joining client uses it for.
+func (s *GravityScene) ApplySnapshot(entities []net.Entity) {
+	s.Bodies = s.Bodies[:0]
+	for _, e := range entities {
+		mass := radiusToMass(float64(e.Radius))
+		s.Bodies = append(s.Bodies, GravityBody{
+			X:     float64(e.X),
+			Y:     float64(e.Y),
+			VX:    float64(e.VX),
+			VY:    float64(e.VY),
+			Mass:  mass,
+			Color: color.RGBA{R: e.R, G: e.G, B: e.B, A: 255},
+		})
 	}
 }
 
@@ -155,9 +272,9 @@ func (s *GravityScene) IsLoaded() bool { return s.loaded }
 func (s *GravityScene) OnEnter()       {}
 func (s *GravityScene) OnExit()        {}
 
-func (s *GravityScene) Update() SceneId {
+func (s *GravityScene) Update() SceneTransition {
 	if ebiten.IsKeyPressed(ebiten.KeyQ) {
-		return StartSceneId
+		return NextScene(StartSceneId, Transition{Kind: TransitionFade, Duration: 400 * time.Millisecond})
 	}
 	// --- Variable gravity ---
 	_, scrollY := ebiten.Wheel()
@@ -241,26 +358,37 @@ func (s *GravityScene) Update() SceneId {
 			}
 		}
 	}
+	return NextScene(GravitySceneId, Transition{})
+}
+
+// FixedUpdate advances gravity, motion, and merging by one
+// physics.Timestep, independent of Ebiten's variable per-frame call rate
+// (see physics.Accumulator). Gravity is computed via a Barnes-Hut
+// quadtree broadphase (buildQuadtree) instead of the O(N^2) direct sum,
+// so hundreds of bodies stay interactive.
+func (s *GravityScene) FixedUpdate(dt time.Duration) {
+	// A joining client just mirrors whatever the host last broadcast -
+	// the host is authoritative, so this scene doesn't also simulate
+	// locally while joined (see app/net.Client, and BallsScene.FixedUpdate
+	// for the same pattern).
+	if s.deps.Net.Joined() {
+		s.ApplySnapshot(s.deps.Net.Client.Entities())
+		return
+	}
+
+	if !s.physEnabled || len(s.Bodies) == 0 {
+		return
+	}
+
 	// --- Gravity ---
-	for i := range s.Bodies {
-		fx, fy := 0.0, 0.0
-		for j := range s.Bodies {
-			if i == j {
-				continue
-			}
-			dx := s.Bodies[j].X - s.Bodies[i].X
-			dy := s.Bodies[j].Y - s.Bodies[i].Y
-			distSq := dx*dx + dy*dy
-			if distSq < 1 {
-				distSq = 1
-			}
-			force := s.gravity * s.Bodies[i].Mass * s.Bodies[j].Mass / distSq
-			angle := math.Atan2(dy, dx)
-			fx += force * math.Cos(angle) / s.Bodies[i].Mass
-			fy += force * math.Sin(angle) / s.Bodies[i].Mass
+	if s.gravityEnabled {
+		tree := s.buildQuadtree()
+		for i := range s.Bodies {
+			b := &s.Bodies[i]
+			ax, ay := tree.ForceOn(i, b.X, b.Y, s.gravity, physics.Theta)
+			b.VX += ax
+			b.VY += ay
 		}
-		s.Bodies[i].VX += fx
-		s.Bodies[i].VY += fy
 	}
 	// --- Move bodies (no border, infinite world) and update trails/flash ---
 	for i := range s.Bodies {
@@ -268,13 +396,15 @@ func (s *GravityScene) Update() SceneId {
 		b.X += b.VX
 		b.Y += b.VY
 		// Update trail
-		trailLen := TrailLength
-		if b.IsComet {
-			trailLen = CometTrailLength
-		}
-		b.Trail = append(b.Trail, TrailPoint{b.X, b.Y})
-		if len(b.Trail) > trailLen {
-			b.Trail = b.Trail[len(b.Trail)-trailLen:]
+		if s.trailsEnabled {
+			trailLen := int(s.trailLength)
+			if b.IsComet {
+				trailLen = CometTrailLength
+			}
+			b.Trail = append(b.Trail, TrailPoint{b.X, b.Y})
+			if len(b.Trail) > trailLen {
+				b.Trail = b.Trail[len(b.Trail)-trailLen:]
+			}
 		}
 		if b.Flash > 0 {
 			b.Flash--
@@ -301,13 +431,37 @@ func (s *GravityScene) Update() SceneId {
 					s.Bodies[i].Trail = s.Bodies[j].Trail
 				}
 				s.Bodies[i].Flash = 10
+				s.deps.Audio.Play("merge")
 				s.Bodies = append(s.Bodies[:j], s.Bodies[j+1:]...)
 			} else {
 				j++
 			}
 		}
 	}
-	return GravitySceneId
+
+	if s.deps.Net.Hosting() {
+		s.deps.Net.Server.Broadcast(s)
+	}
+}
+
+// buildQuadtree bins the current bodies into a fresh Barnes-Hut tree
+// spanning their bounding box, for FixedUpdate's gravity pass. It's
+// rebuilt every tick since bodies move every tick - there's no reuse to
+// be had the way SpatialHash's buckets allow for BallsScene.
+func (s *GravityScene) buildQuadtree() *physics.Quadtree {
+	minX, minY := s.Bodies[0].X, s.Bodies[0].Y
+	maxX, maxY := minX, minY
+	for _, b := range s.Bodies[1:] {
+		minX = math.Min(minX, b.X)
+		minY = math.Min(minY, b.Y)
+		maxX = math.Max(maxX, b.X)
+		maxY = math.Max(maxY, b.Y)
+	}
+	tree := physics.NewQuadtree(minX-gravityAABBMargin, minY-gravityAABBMargin, maxX+gravityAABBMargin, maxY+gravityAABBMargin)
+	for i, b := range s.Bodies {
+		tree.Insert(physics.Body{ID: i, X: b.X, Y: b.Y, Mass: b.Mass})
+	}
+	return tree
 }
 
 // Convert screen (x, y) to world coordinates (taking zoom and pan into account)
@@ -324,6 +478,15 @@ func massToRadius(m float64) float64 {
 	return 6 + math.Sqrt(m)*1.5 // Not linear, tweak as needed
 }
 
+// radiusToMass inverts massToRadius, for a joining client reconstructing
+// an approximate Mass from the Radius a host's Entities sent over the
+// wire (see GravityScene.ApplySnapshot) - Mass itself isn't part of
+// net.Entity.
+func radiusToMass(r float64) float64 {
+	t := (r - 6) / 1.5
+	return t * t
+}
+
 func massToColor(m float64) color.Color {
 	// Blue for small, red for big
 	t := (m - MinMass) / (MaxMass - MinMass)
@@ -347,3 +510,8 @@ func bodyAt(bodies []GravityBody, x, y float64) bool {
 }
 
 var _ Scene = (*GravityScene)(nil)
+var _ FixedUpdater = (*GravityScene)(nil)
+var _ console.CvarSource = (*GravityScene)(nil)
+var _ console.Spawner = (*GravityScene)(nil)
+var _ net.Source = (*GravityScene)(nil)
+var _ console.Clearer = (*GravityScene)(nil)
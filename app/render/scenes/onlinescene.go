@@ -0,0 +1,273 @@
+package scenes
+
+import (
+	"fmt"
+	"image/color"
+	"net/http"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	log "github.com/sirupsen/logrus"
+
+	"app/events"
+	onlinenet "app/net"
+	"app/ui"
+)
+
+const (
+	// defaultListenAddr/defaultServerAddr are used whenever
+	// Config.Net.ListenAddr/ServerAddr is left blank, so the online
+	// sandbox works out of the box on a single machine.
+	defaultListenAddr = ":9000"
+	defaultServerAddr = "ws://localhost:9000/ws"
+
+	// onlineWSPath is where Server's http.Handler is mounted; Client.Dial
+	// always connects to <server address>/ws.
+	onlineWSPath = "/ws"
+)
+
+// onlineMode tracks which step of hosting/joining OnlineScene is in.
+type onlineMode int
+
+const (
+	onlineModeMenu      onlineMode = iota // choosing Host or Join
+	onlineModeJoinInput                   // typing the server address to join
+	onlineModeConnected                   // hosting or joined; ready to play
+)
+
+func init() {
+	RegisterScene(SceneDescriptor{
+		ID:   OnlineSceneId,
+		Name: "Online Sandbox",
+		Factory: func(deps *Deps) Scene {
+			return NewOnlineScene(deps)
+		},
+		ShowInMenu: true,
+		MenuOrder:  6,
+	})
+}
+
+// OnlineScene lets the player host or join a shared BallsScene or
+// GravityScene sandbox over a websocket (see app/net). It only manages
+// the connection - once hosting or joined, Enter/G hand off to
+// BallsScene/GravityScene with deps.Net already populated, and the
+// session intentionally stays live across that transition (and any
+// further scene switches) until the player comes back here and
+// disconnects, or the process exits.
+type OnlineScene struct {
+	BaseScene
+	loaded bool
+	deps   *Deps
+
+	mode   onlineMode
+	status string
+
+	addressInput *ui.TextInput
+
+	// httpServer is non-nil only while hosting; OnlineScene owns starting
+	// and stopping it, since app/net.Server itself is just the
+	// http.Handler, not the listener.
+	httpServer *http.Server
+}
+
+func NewOnlineScene(deps *Deps) *OnlineScene {
+	scene := &OnlineScene{
+		deps: deps,
+	}
+	scene.addressInput = ui.NewTextInput(ui.TextInputConfig{
+		Font:        deps.Assets.Font(),
+		Scale:       2,
+		MaxWidth:    600,
+		Placeholder: serverAddrOrDefault(deps),
+		OnDirty: func() {
+			scene.deps.Frames.Request()
+		},
+	})
+	return scene
+}
+
+func serverAddrOrDefault(deps *Deps) string {
+	if deps.Config.Net.ServerAddr != "" {
+		return deps.Config.Net.ServerAddr
+	}
+	return defaultServerAddr
+}
+
+func listenAddrOrDefault(deps *Deps) string {
+	if deps.Config.Net.ListenAddr != "" {
+		return deps.Config.Net.ListenAddr
+	}
+	return defaultListenAddr
+}
+
+func (s *OnlineScene) GetName() string {
+	return "Online Sandbox"
+}
+
+func (s *OnlineScene) FirstLoad() {
+	s.loaded = true
+}
+
+func (s *OnlineScene) IsLoaded() bool {
+	return s.loaded
+}
+
+func (s *OnlineScene) OnEnter() {
+	s.deps.Frames.Request()
+	if s.deps.Net.Hosting() {
+		s.mode = onlineModeConnected
+		s.status = fmt.Sprintf("Hosting on %s%s", listenAddrOrDefault(s.deps), onlineWSPath)
+	} else if s.deps.Net.Joined() {
+		s.mode = onlineModeConnected
+		s.status = "Joined " + serverAddrOrDefault(s.deps)
+	}
+}
+
+func (s *OnlineScene) OnExit() {}
+
+// HandleEvent discards every event; OnlineScene polls input directly in
+// Update, the same way StartScene does.
+func (s *OnlineScene) HandleEvent(ev events.Event) {}
+
+func (s *OnlineScene) Update() SceneTransition {
+	// Escape backs out to the main menu from the menu or a connected
+	// session; while typing an address, Escape instead cancels just the
+	// input field (see TextInput.Cancelled in updateJoinInput).
+	if s.mode != onlineModeJoinInput && inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		return NextScene(StartSceneId, Transition{Kind: TransitionFade, Duration: 400 * time.Millisecond})
+	}
+
+	switch s.mode {
+	case onlineModeJoinInput:
+		s.updateJoinInput()
+	case onlineModeMenu:
+		s.updateMenu()
+	case onlineModeConnected:
+		return s.updateConnected()
+	}
+	return NextScene(OnlineSceneId, Transition{})
+}
+
+func (s *OnlineScene) updateMenu() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyH) {
+		s.startHosting()
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyJ) {
+		s.mode = onlineModeJoinInput
+		s.addressInput.Activate()
+	}
+}
+
+func (s *OnlineScene) updateJoinInput() {
+	if s.addressInput.Active() {
+		s.addressInput.Update()
+	}
+	select {
+	case addr := <-s.addressInput.Submitted():
+		if addr == "" {
+			addr = serverAddrOrDefault(s.deps)
+		}
+		s.joinServer(addr)
+	default:
+	}
+	select {
+	case <-s.addressInput.Cancelled():
+		s.mode = onlineModeMenu
+	default:
+	}
+}
+
+func (s *OnlineScene) updateConnected() SceneTransition {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		return NextScene(BallsSceneId, Transition{Kind: TransitionFade, Duration: 400 * time.Millisecond})
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyG) {
+		return NextScene(GravitySceneId, Transition{Kind: TransitionFade, Duration: 400 * time.Millisecond})
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyD) {
+		s.disconnect()
+	}
+	return NextScene(OnlineSceneId, Transition{})
+}
+
+// startHosting starts a net.Server and serves it in the background on
+// Config.Net.ListenAddr (or defaultListenAddr).
+func (s *OnlineScene) startHosting() {
+	addr := listenAddrOrDefault(s.deps)
+	server := onlinenet.NewServer()
+	mux := http.NewServeMux()
+	mux.Handle(onlineWSPath, server)
+	s.httpServer = &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.WithError(err).Error("Online sandbox server stopped unexpectedly")
+		}
+	}()
+
+	s.deps.Net = &onlinenet.Session{Server: server}
+	s.mode = onlineModeConnected
+	s.status = fmt.Sprintf("Hosting on %s%s", addr, onlineWSPath)
+	s.deps.Frames.Request()
+	log.WithField("addr", addr).Info("Started online sandbox host")
+}
+
+// joinServer dials addr as a net.Client.
+func (s *OnlineScene) joinServer(addr string) {
+	client, err := onlinenet.Dial(addr)
+	if err != nil {
+		log.WithError(err).WithField("addr", addr).Warn("Failed to join online sandbox server")
+		s.status = fmt.Sprintf("Failed to join %s: %v", addr, err)
+		s.mode = onlineModeMenu
+		s.deps.Frames.Request()
+		return
+	}
+
+	s.deps.Net = &onlinenet.Session{Client: client}
+	s.mode = onlineModeConnected
+	s.status = "Joined " + addr
+	s.deps.Frames.Request()
+	log.WithField("addr", addr).Info("Joined online sandbox server")
+}
+
+// disconnect tears down whichever side of the session is active and
+// returns to the host/join menu.
+func (s *OnlineScene) disconnect() {
+	if s.httpServer != nil {
+		s.httpServer.Close()
+		s.httpServer = nil
+	}
+	s.deps.Net.Close()
+	s.deps.Net = nil
+	s.mode = onlineModeMenu
+	s.status = ""
+	s.deps.Frames.Request()
+}
+
+func (s *OnlineScene) Draw(screen *ebiten.Image) {
+	width := float32(s.deps.Config.Render.Window.Width)
+	height := float32(s.deps.Config.Render.Window.Height)
+	vector.DrawFilledRect(screen, 0, 0, width, height, color.RGBA{20, 20, 30, 255}, false)
+
+	font := s.deps.Assets.Font()
+	font.DrawText(screen, "Online Sandbox", 40, 40, 3, color.White)
+
+	switch s.mode {
+	case onlineModeMenu:
+		font.DrawText(screen, "H: Host a game   J: Join a game   Esc: back", 40, 120, 2, color.White)
+		if s.status != "" {
+			font.DrawText(screen, s.status, 40, 160, 2, color.RGBA{255, 120, 120, 255})
+		}
+	case onlineModeJoinInput:
+		font.DrawText(screen, "Server address (Enter to join, Esc to cancel):", 40, 120, 2, color.White)
+		s.addressInput.Draw(screen, 40, 160)
+	case onlineModeConnected:
+		font.DrawText(screen, s.status, 40, 120, 2, color.White)
+		font.DrawText(screen, "Enter: Balls   G: Gravity   D: disconnect   Esc: back", 40, 160, 2, color.White)
+	}
+}
+
+var _ Scene = (*OnlineScene)(nil)
@@ -1,7 +1,12 @@
 package scenes
 
 import (
+	"time"
+
 	"app/common"
+	"app/events"
+	"app/net"
+	"app/services"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
@@ -14,6 +19,8 @@ const (
 	GravitySceneId
 	ComfyUISceneId
 	GameOverSceneId
+	DungeonSceneId
+	OnlineSceneId
 
 	// Special scene
 	ExitSceneId // Exit the game
@@ -21,16 +28,71 @@ const (
 
 type Scene interface {
 	GetName() string
-	Update() SceneId
+
+	// Update advances the scene by one tick and reports which scene
+	// should be active next, via NextScene. Returning the scene's own id
+	// means "stay here"; SceneManager ignores the Transition in that case.
+	Update() SceneTransition
 	Draw(screen *ebiten.Image)
 	FirstLoad()
 	OnEnter()
 	OnExit()
 	IsLoaded() bool
+
+	// HandleEvent lets a scene react to discrete, edge-triggered events
+	// (input transitions, lifecycle, window/shutdown) published on
+	// Deps.Bus, as an alternative to polling every frame in Update. Game
+	// calls it once per event, before Update. Embed BaseScene to get a
+	// no-op default.
+	HandleEvent(ev events.Event)
+
+	// Dirty reports whether the scene needs to be redrawn since the last
+	// Draw call. The top-level Game skips rendering when it doesn't, to
+	// avoid burning GPU time on an unchanged screen. Embed BaseScene to
+	// get a default that always reports dirty.
+	Dirty() bool
+}
+
+// FixedUpdater is an optional interface for scenes whose simulation must
+// run at a fixed timestep, independent of how often (and how regularly)
+// Game.Update is actually invoked - see app/physics.Accumulator. If the
+// active scene implements it, Game calls FixedUpdate zero or more times
+// per frame (once per accumulated physics.Timestep) immediately before
+// the scene's regular, variable-rate Update.
+type FixedUpdater interface {
+	FixedUpdate(dt time.Duration)
 }
 
 type Deps struct {
-	Config *common.Config
+	Config       *common.Config
+	Assets       *AssetStore
+	Audio        *AudioRegistry
+	SceneManager *SceneManager
+	ComfyUI      *services.ComfyUIService
+	Startup      *StartupFlags
+	Frames       *FrameScheduler
+
+	// Bus is the shared event bus Game publishes discrete input and
+	// lifecycle events to (see app/events). Scenes receive these via
+	// HandleEvent; other subsystems (audio, networking) can Subscribe
+	// directly without touching render code.
+	Bus *events.Bus
+
+	// Net holds the online sandbox's active host/join session, if any
+	// (see app/net and OnlineScene). It's nil until OnlineScene starts
+	// hosting or joining, and every *net.Session method is nil-safe, so
+	// scenes can check deps.Net.Hosting()/Joined() unconditionally.
+	Net *net.Session
+}
+
+// StartupFlags carries the command-line startup options parsed in main
+// (see flags.go/flags_web.go) down to the scenes that care about them.
+type StartupFlags struct {
+	SkipTitle   bool   // jump straight to SceneName instead of showing StartScene
+	SceneName   string // scene to jump to when SkipTitle is set, resolved via SceneManager.LookupByName
+	Fullscreen  bool   // start the window in fullscreen mode
+	DebugFPS    bool   // overlay the current FPS/TPS on screen
+	EscapeExits bool   // whether pressing Escape quits the game
 }
 
 /*
@@ -53,8 +115,9 @@ import (
 )
 
 type MyScene struct {
-	loaded bool
-	deps   *Deps
+	BaseScene // default Dirty() that always redraws; override it if your scene can skip frames
+	loaded    bool
+	deps      *Deps
 	// Add your scene-specific fields here
 }
 
@@ -69,16 +132,17 @@ func (s *MyScene) GetName() string {
 	return "MyScene"
 }
 
-func (s *MyScene) Update() SceneId {
+func (s *MyScene) Update() SceneTransition {
 	// Handle escape key to exit
 	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
-		return ExitSceneId
+		return NextScene(ExitSceneId, Transition{})
 	}
 
 	// Handle input and update scene logic
-	// Return the SceneId for the next scene or current scene
+	// Return NextScene(id, transition) for the scene to switch to, or
+	// your own scene id to stay here
 
-	return MySceneId
+	return NextScene(MySceneId, Transition{})
 }
 
 func (s *MyScene) Draw(screen *ebiten.Image) {
@@ -103,6 +167,10 @@ func (s *MyScene) IsLoaded() bool {
 	return s.loaded
 }
 
+// HandleEvent is already provided by the embedded BaseScene as a no-op;
+// override it if your scene wants edge-triggered input or lifecycle
+// events instead of (or in addition to) polling in Update.
+
 // Verify interface compliance
 var _ Scene = (*MyScene)(nil)
 
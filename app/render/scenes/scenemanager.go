@@ -0,0 +1,149 @@
+package scenes
+
+import (
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"app/events"
+)
+
+// SceneDescriptor describes a scene for the registry: how to build it, its
+// display name, and where (if at all) it shows up in the start menu.
+type SceneDescriptor struct {
+	ID         SceneId
+	Name       string
+	Factory    func(deps *Deps) Scene
+	ShowInMenu bool
+	MenuOrder  int
+}
+
+// registry holds every scene descriptor registered via RegisterScene,
+// normally called from a scene's package-level init(). This lets new
+// scenes add themselves to the start menu without anyone editing it.
+var registry = map[SceneId]SceneDescriptor{}
+
+// RegisterScene adds desc to the set of known scenes.
+func RegisterScene(desc SceneDescriptor) {
+	registry[desc.ID] = desc
+}
+
+// SceneManager lazily instantiates and caches scenes from the registry and
+// tracks which one is currently active.
+type SceneManager struct {
+	deps *Deps
+
+	instances  map[SceneId]Scene
+	current    SceneId
+	hasCurrent bool
+
+	// transition is non-nil while a Begin-initiated transition effect is
+	// playing; see scenetransition.go.
+	transition *transitionState
+}
+
+// NewSceneManager creates a manager bound to deps. Scene instances are
+// created lazily, the first time they're requested.
+func NewSceneManager(deps *Deps) *SceneManager {
+	return &SceneManager{
+		deps:      deps,
+		instances: make(map[SceneId]Scene),
+	}
+}
+
+// MenuEntries returns the registered descriptors with ShowInMenu set,
+// sorted by MenuOrder.
+func (m *SceneManager) MenuEntries() []SceneDescriptor {
+	entries := make([]SceneDescriptor, 0, len(registry))
+	for _, desc := range registry {
+		if desc.ShowInMenu {
+			entries = append(entries, desc)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].MenuOrder < entries[j].MenuOrder
+	})
+	return entries
+}
+
+// LookupByName returns the id of the registered scene whose Name matches
+// name, ignoring case. This lets callers (e.g. a -scene command-line flag)
+// resolve a scene by its display name instead of its numeric id.
+func (m *SceneManager) LookupByName(name string) (SceneId, bool) {
+	for id, desc := range registry {
+		if strings.EqualFold(desc.Name, name) {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// Get returns the scene registered under id, instantiating it via its
+// Factory on first use. The bool result is false if no scene is
+// registered under id.
+func (m *SceneManager) Get(id SceneId) (Scene, bool) {
+	if scene, ok := m.instances[id]; ok {
+		return scene, true
+	}
+
+	desc, ok := registry[id]
+	if !ok {
+		return nil, false
+	}
+
+	log.WithFields(log.Fields{"scene_id": id, "scene_name": desc.Name}).Debug("Instantiating scene")
+	scene := desc.Factory(m.deps)
+	m.instances[id] = scene
+	return scene, true
+}
+
+// Current returns the currently active scene id. It's only meaningful
+// after at least one call to Goto.
+func (m *SceneManager) Current() SceneId {
+	return m.current
+}
+
+// Goto switches the active scene to id, calling OnExit on the outgoing
+// scene and FirstLoad (if needed)/OnEnter on the incoming one. It's a
+// no-op if id is already current.
+func (m *SceneManager) Goto(id SceneId) {
+	if m.hasCurrent && id == m.current {
+		return
+	}
+
+	if m.hasCurrent {
+		if current, ok := m.Get(m.current); ok {
+			log.WithField("scene_id", m.current).Debug("Calling OnExit for active scene")
+			m.publish(events.SceneExitEvent{Scene: registry[m.current].Name})
+			current.OnExit()
+		}
+	}
+
+	log.WithFields(log.Fields{"from_scene": m.current, "to_scene": id}).Info("Scene transition")
+	m.current = id
+	m.hasCurrent = true
+
+	next, ok := m.Get(id)
+	if !ok {
+		log.WithField("scene_id", id).Error("Requested scene does not exist")
+		return
+	}
+
+	if !next.IsLoaded() {
+		log.WithField("scene_id", id).Debug("Loading scene for first time")
+		next.FirstLoad()
+	}
+
+	log.WithField("scene_id", id).Debug("Calling OnEnter for new scene")
+	m.publish(events.SceneEnterEvent{Scene: registry[id].Name})
+	next.OnEnter()
+}
+
+// publish is a nil-safe wrapper around deps.Bus.Publish, since Deps.Bus is
+// optional.
+func (m *SceneManager) publish(ev events.Event) {
+	if m.deps.Bus != nil {
+		m.deps.Bus.Publish(ev)
+	}
+}
@@ -0,0 +1,218 @@
+package scenes
+
+import (
+	"image/color"
+	"sync/atomic"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	log "github.com/sirupsen/logrus"
+)
+
+// transitionState holds everything SceneManager needs to animate a scene
+// change: a snapshot of the outgoing scene to composite against, and the
+// incoming scene's preload progress (it runs in a goroutine so a
+// heavyweight scene like ComfyUIScene can load while the effect plays).
+type transitionState struct {
+	kind     TransitionKind
+	duration time.Duration
+	elapsed  time.Duration
+
+	toId    SceneId
+	toReady atomic.Bool
+
+	from *ebiten.Image // snapshot of the outgoing scene's last frame
+	to   *ebiten.Image // scratch buffer the incoming scene is re-drawn into each frame
+}
+
+// Begin starts a transition from the current scene to id using the given
+// effect. The incoming scene's FirstLoad (if it hasn't run yet) happens in
+// a background goroutine, so e.g. ComfyUIScene can preload while the
+// outgoing scene's snapshot stays on screen. Goto is used instead when the
+// transition is instant or there's no current scene yet.
+func (m *SceneManager) Begin(id SceneId, transition Transition) {
+	if m.hasCurrent && id == m.current {
+		return
+	}
+
+	if !m.hasCurrent || transition.Kind == TransitionNone || transition.Duration <= 0 {
+		m.Goto(id)
+		return
+	}
+
+	next, ok := m.Get(id)
+	if !ok {
+		log.WithField("scene_id", id).Error("Requested scene does not exist")
+		return
+	}
+
+	w := m.deps.Config.Render.Window.Width
+	h := m.deps.Config.Render.Window.Height
+
+	snapshot := ebiten.NewImage(w, h)
+	if current, ok := m.Get(m.current); ok {
+		current.Draw(snapshot)
+	}
+
+	state := &transitionState{
+		kind:     transition.Kind,
+		duration: transition.Duration,
+		toId:     id,
+		from:     snapshot,
+		to:       ebiten.NewImage(w, h),
+	}
+
+	if next.IsLoaded() {
+		state.toReady.Store(true)
+	} else {
+		go func() {
+			log.WithField("scene_id", id).Debug("Preloading incoming scene for transition")
+			next.FirstLoad()
+			state.toReady.Store(true)
+		}()
+	}
+
+	m.transition = state
+	log.WithFields(log.Fields{
+		"from_scene": m.current,
+		"to_scene":   id,
+		"kind":       transition.Kind,
+		"duration":   transition.Duration,
+	}).Info("Scene transition started")
+}
+
+// Transitioning reports whether a transition effect is currently playing.
+func (m *SceneManager) Transitioning() bool {
+	return m.transition != nil
+}
+
+// Advance steps the active transition (if any) by dt. Once the timer has
+// elapsed and the incoming scene has finished preloading, it completes the
+// transition: OnExit on the outgoing scene, then FirstLoad (already done,
+// if it ran)/OnEnter on the incoming one, exactly as Goto would.
+func (m *SceneManager) Advance(dt time.Duration) {
+	t := m.transition
+	if t == nil {
+		return
+	}
+
+	t.elapsed += dt
+	if t.elapsed < t.duration || !t.toReady.Load() {
+		return
+	}
+
+	if current, ok := m.Get(m.current); ok {
+		log.WithField("scene_id", m.current).Debug("Calling OnExit for active scene")
+		current.OnExit()
+	}
+
+	m.current = t.toId
+	m.hasCurrent = true
+
+	if next, ok := m.Get(t.toId); ok {
+		log.WithField("scene_id", t.toId).Debug("Calling OnEnter for new scene")
+		next.OnEnter()
+	}
+
+	m.transition = nil
+}
+
+// Draw renders the active scene, or - mid-transition - composites the
+// outgoing snapshot and the incoming scene using the selected effect.
+func (m *SceneManager) Draw(screen *ebiten.Image) {
+	t := m.transition
+	if t == nil {
+		if current, ok := m.Get(m.current); ok {
+			current.Draw(screen)
+		}
+		return
+	}
+
+	progress := 1.0
+	if t.duration > 0 {
+		progress = float64(t.elapsed) / float64(t.duration)
+	}
+	if progress > 1 {
+		progress = 1
+	}
+
+	switch t.kind {
+	case TransitionFade:
+		m.drawFade(screen, t, progress)
+	case TransitionCrossfade:
+		m.drawCrossfade(screen, t, progress)
+	case TransitionSlide:
+		m.drawSlide(screen, t, progress)
+	default:
+		screen.DrawImage(t.from, nil)
+	}
+}
+
+// drawFade dims the outgoing snapshot to black over the first half of the
+// transition, then brings the incoming scene up from black over the
+// second half. While the incoming scene is still preloading, it holds on
+// a black screen rather than showing a half-loaded frame.
+func (m *SceneManager) drawFade(screen *ebiten.Image, t *transitionState, progress float64) {
+	if progress < 0.5 {
+		screen.DrawImage(t.from, nil)
+		overlayBlack(screen, progress*2)
+		return
+	}
+
+	if next, ok := m.Get(t.toId); ok && t.toReady.Load() {
+		next.Draw(screen)
+	}
+	overlayBlack(screen, 1-(progress-0.5)*2)
+}
+
+// drawCrossfade dissolves from the outgoing snapshot to the incoming
+// scene, re-rendering the incoming scene into a scratch buffer each frame
+// so it keeps animating throughout the transition.
+func (m *SceneManager) drawCrossfade(screen *ebiten.Image, t *transitionState, progress float64) {
+	screen.DrawImage(t.from, nil)
+
+	next, ok := m.Get(t.toId)
+	if !ok || !t.toReady.Load() {
+		return
+	}
+
+	t.to.Clear()
+	next.Draw(t.to)
+
+	op := &ebiten.DrawImageOptions{}
+	op.ColorScale.ScaleAlpha(float32(progress))
+	screen.DrawImage(t.to, op)
+}
+
+// drawSlide slides the incoming scene in from the right over the outgoing
+// snapshot.
+func (m *SceneManager) drawSlide(screen *ebiten.Image, t *transitionState, progress float64) {
+	screen.DrawImage(t.from, nil)
+
+	next, ok := m.Get(t.toId)
+	if !ok || !t.toReady.Load() {
+		return
+	}
+
+	t.to.Clear()
+	next.Draw(t.to)
+
+	width := screen.Bounds().Dx()
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(width)*(1-progress), 0)
+	screen.DrawImage(t.to, op)
+}
+
+// overlayBlack draws a full-screen black rectangle at the given alpha
+// (0-1), used by drawFade to dim in and out of the transition.
+func overlayBlack(screen *ebiten.Image, alpha float64) {
+	if alpha <= 0 {
+		return
+	}
+	if alpha > 1 {
+		alpha = 1
+	}
+	bounds := screen.Bounds()
+	vector.DrawFilledRect(screen, 0, 0, float32(bounds.Dx()), float32(bounds.Dy()), color.RGBA{0, 0, 0, uint8(alpha * 255)}, false)
+}
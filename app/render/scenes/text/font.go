@@ -0,0 +1,169 @@
+// Package text draws scaled, shadowed bitmap text for scenes that need
+// more than ebitenutil.DebugPrintAt's fixed 6px debug font, e.g. to get
+// accurate width measurements for centering or a scalable title.
+package text
+
+import (
+	"image"
+	"image/color"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Font draws text by blitting fixed-size glyphs out of a single atlas
+// texture baked from a golang.org/x/image font.Face, instead of
+// rasterizing each string every frame.
+type Font struct {
+	atlas  *ebiten.Image
+	glyphW int
+	glyphH int
+	first  rune
+	last   rune
+	cols   int
+}
+
+// firstGlyph and lastGlyph bound the printable ASCII range baked into
+// every atlas; scenes in this game don't need anything outside it.
+const (
+	firstGlyph = ' '
+	lastGlyph  = '~'
+	atlasCols  = 16
+)
+
+// NewFont bakes every glyph in face's printable ASCII range into a single
+// atlas image, arranged in a fixed-size grid so glyph lookup is a simple
+// index computation.
+func NewFont(face font.Face) *Font {
+	n := int(lastGlyph-firstGlyph) + 1
+	rows := (n + atlasCols - 1) / atlasCols
+
+	metrics := face.Metrics()
+	glyphW := 0
+	for r := rune(firstGlyph); r <= lastGlyph; r++ {
+		adv, ok := face.GlyphAdvance(r)
+		if !ok {
+			continue
+		}
+		if w := adv.Ceil(); w > glyphW {
+			glyphW = w
+		}
+	}
+	glyphH := metrics.Height.Ceil()
+	ascent := metrics.Ascent.Ceil()
+
+	atlasImg := image.NewRGBA(image.Rect(0, 0, atlasCols*glyphW, rows*glyphH))
+	drawer := &font.Drawer{
+		Dst:  atlasImg,
+		Src:  image.NewUniform(color.White),
+		Face: face,
+	}
+	for i := 0; i < n; i++ {
+		col, row := i%atlasCols, i/atlasCols
+		drawer.Dot = fixed.P(col*glyphW, row*glyphH+ascent)
+		drawer.DrawString(string(rune(firstGlyph + i)))
+	}
+
+	return &Font{
+		atlas:  ebiten.NewImageFromImage(atlasImg),
+		glyphW: glyphW,
+		glyphH: glyphH,
+		first:  firstGlyph,
+		last:   lastGlyph,
+		cols:   atlasCols,
+	}
+}
+
+var (
+	defaultOnce sync.Once
+	defaultFont *Font
+)
+
+// DefaultFont returns the process-wide default font, baked lazily from
+// Go's built-in basicfont.Face7x13 so no font asset file is required.
+func DefaultFont() *Font {
+	defaultOnce.Do(func() {
+		defaultFont = NewFont(basicfont.Face7x13)
+	})
+	return defaultFont
+}
+
+// GlyphSize returns the unscaled pixel size of a single glyph cell.
+func (f *Font) GlyphSize() (w, h int) {
+	return f.glyphW, f.glyphH
+}
+
+func (f *Font) glyphRect(r rune) (image.Rectangle, bool) {
+	if r < f.first || r > f.last {
+		return image.Rectangle{}, false
+	}
+	i := int(r - f.first)
+	col, row := i%f.cols, i/f.cols
+	x, y := col*f.glyphW, row*f.glyphH
+	return image.Rect(x, y, x+f.glyphW, y+f.glyphH), true
+}
+
+// DrawText draws str with its top-left corner at (x, y), scaling each
+// glyph by an integer factor and tinting it to clr. Unknown runes are
+// skipped but still advance the cursor, and '\n' starts a new line.
+func (f *Font) DrawText(screen *ebiten.Image, str string, x, y, scale int, clr color.Color) {
+	if scale < 1 {
+		scale = 1
+	}
+
+	cx, cy := x, y
+	for _, r := range str {
+		if r == '\n' {
+			cx = x
+			cy += f.glyphH * scale
+			continue
+		}
+
+		rect, ok := f.glyphRect(r)
+		if ok {
+			op := &ebiten.DrawImageOptions{}
+			op.ColorScale.ScaleWithColor(clr)
+			op.GeoM.Scale(float64(scale), float64(scale))
+			op.GeoM.Translate(float64(cx), float64(cy))
+			screen.DrawImage(f.atlas.SubImage(rect).(*ebiten.Image), op)
+		}
+		cx += f.glyphW * scale
+	}
+}
+
+// DrawTextWithShadow draws str twice - once offset by shadowOffset in
+// shadowClr, then again at (x, y) in clr - so it stays readable over a
+// busy or animated background.
+func (f *Font) DrawTextWithShadow(screen *ebiten.Image, str string, x, y, scale int, clr, shadowClr color.Color, shadowOffset int) {
+	f.DrawText(screen, str, x+shadowOffset, y+shadowOffset, scale, shadowClr)
+	f.DrawText(screen, str, x, y, scale, clr)
+}
+
+// Measure returns the pixel footprint str would occupy if drawn at scale,
+// so callers can center or box text exactly instead of estimating from
+// len(str).
+func (f *Font) Measure(str string, scale int) (w, h int) {
+	if scale < 1 {
+		scale = 1
+	}
+
+	lineW, maxW, lines := 0, 0, 1
+	for _, r := range str {
+		if r == '\n' {
+			lines++
+			if lineW > maxW {
+				maxW = lineW
+			}
+			lineW = 0
+			continue
+		}
+		lineW += f.glyphW * scale
+	}
+	if lineW > maxW {
+		maxW = lineW
+	}
+	return maxW, lines * f.glyphH * scale
+}
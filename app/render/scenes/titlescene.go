@@ -1,55 +1,118 @@
 package scenes
 
 import (
+	"fmt"
 	"image/color"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 	log "github.com/sirupsen/logrus"
+
+	"app/events"
+	"app/render/scenes/background"
+	"app/render/scenes/text"
+)
+
+const (
+	titleBackgroundTexture = "assets/images/title_bg_tile.png"
+	titleTileSize          = 64
+
+	// StartSceneName is StartScene's registered display name, exported so
+	// other packages (e.g. Game's menu-music handling) can recognize it
+	// from an events.SceneEnterEvent/SceneExitEvent without hardcoding the
+	// string themselves.
+	StartSceneName = "Main Menu"
 )
 
+func init() {
+	RegisterScene(SceneDescriptor{
+		ID:   StartSceneId,
+		Name: StartSceneName,
+		Factory: func(deps *Deps) Scene {
+			return NewStartScene(deps)
+		},
+		ShowInMenu: false,
+	})
+}
+
 type StartScene struct {
 	loaded bool
 	deps   *Deps
 
 	// Scene selector
 	selectedScene int
-	scenes        []SceneOption
-}
+	menu          []SceneDescriptor
 
-type SceneOption struct {
-	id   SceneId
-	name string
+	// Background animation
+	count    int
+	bgTile   *ebiten.Image
+	parallax []*background.BackgroundLayer
+
+	// dirty tracks whether the scene needs to be redrawn; see Dirty().
+	dirty bool
 }
 
+// backgroundTickFrames is how often (in frames) the scrolling title tile
+// actually shifts by a visible amount - drawTitleBackground offsets it by
+// count/4, so ticks in between wouldn't change a single pixel.
+const backgroundTickFrames = 4
+
 func NewStartScene(deps *Deps) *StartScene {
 	log.Info("Creating new start scene (title screen)")
 
-	// Build from deps.Scenes
-	scenesOptions := []SceneOption{
-		{id: BallsSceneId, name: "Balls Physics Demo"},
-		{id: GravitySceneId, name: "Gravity Demo"},
-		{id: ComfyUISceneId, name: "ComfyUI Demo"},
-		{id: GameOverSceneId, name: "Game Over"},
-	}
-
-	log.WithField("available_scenes", len(scenesOptions)).Info("Built scene options from dependencies")
-	for i, option := range scenesOptions {
+	menu := deps.SceneManager.MenuEntries()
+	log.WithField("available_scenes", len(menu)).Info("Built scene menu from the scene registry")
+	for i, option := range menu {
 		log.WithFields(log.Fields{
 			"index":      i,
-			"scene_id":   option.id,
-			"scene_name": option.name,
+			"scene_id":   option.ID,
+			"scene_name": option.Name,
 		}).Debug("Added scene option")
 	}
 
-	return &StartScene{
+	scene := &StartScene{
 		loaded:        false,
 		deps:          deps,
 		selectedScene: 0,
-		scenes:        scenesOptions,
+		menu:          menu,
+		dirty:         true,
 	}
+	scene.loadBackground()
+
+	return scene
+}
+
+// loadBackground loads the tiled title texture and builds the parallax
+// layers drawn behind it. If the texture can't be loaded (e.g. missing
+// asset), a procedural tile is generated instead so the menu still renders.
+func (s *StartScene) loadBackground() {
+	tile, err := s.deps.Assets.LoadImage(titleBackgroundTexture)
+	if err != nil {
+		log.WithError(err).Warn("Falling back to procedural title background tile")
+		tile = generateFallbackTile(titleTileSize)
+	}
+	s.bgTile = tile
+
+	s.parallax = []*background.BackgroundLayer{
+		background.NewBackgroundLayer(tile, background.Velocity{X: 0.1, Y: 0.2}, 0.35),
+		background.NewBackgroundLayer(tile, background.Velocity{X: -0.25, Y: 0.1}, 0.2),
+	}
+}
+
+// generateFallbackTile builds a simple checkerboard tile so the background
+// still has something to scroll when the real texture asset is missing.
+func generateFallbackTile(size int) *ebiten.Image {
+	tile := ebiten.NewImage(size, size)
+	dark := color.RGBA{80, 20, 10, 255}
+	light := color.RGBA{120, 30, 20, 255}
+	half := float32(size) / 2
+	vector.DrawFilledRect(tile, 0, 0, half, half, light, false)
+	vector.DrawFilledRect(tile, half, half, half, half, light, false)
+	vector.DrawFilledRect(tile, half, 0, half, half, dark, false)
+	vector.DrawFilledRect(tile, 0, half, half, half, dark, false)
+	return tile
 }
 
 func (s *StartScene) GetName() string {
@@ -63,52 +126,106 @@ func (s *StartScene) Draw(screen *ebiten.Image) {
 	log.WithFields(log.Fields{
 		"screen_width":   width,
 		"screen_height":  height,
-		"scene_count":    len(s.scenes),
+		"scene_count":    len(s.menu),
 		"selected_index": s.selectedScene,
 	}).Trace("Drawing title scene")
 
-	// Draw gradient background
-	s.drawGradientBackground(screen, width, height)
+	// Draw animated, tiled parallax background
+	s.drawTitleBackground(screen, width, height)
+
+	font := s.deps.Assets.Font()
 
 	// Draw title with shadow and background
 	title := "Infinite Dungeon"
 	titleX := width / 2
 	titleY := height * 0.18
 	titleFontSize := 36
+	titleScale := scaleForPixelHeight(font, titleFontSize)
 	titleBoxPadding := 16
-	textW := len(title) * titleFontSize / 2
+	textW, textH := font.Measure(title, titleScale)
 	boxW := float32(textW) + float32(titleBoxPadding*2)
-	boxH := float32(titleFontSize) + float32(titleBoxPadding*2)
+	boxH := float32(textH) + float32(titleBoxPadding*2)
 	boxX := titleX - boxW/2
 	boxY := titleY - float32(titleBoxPadding)
 	// Draw semi-transparent box
 	vector.DrawFilledRect(screen, boxX, boxY, boxW, boxH, color.RGBA{0, 0, 0, 180}, false)
-	// Draw shadow
-	ebitenutil.DebugPrintAt(screen, title, int(titleX)-textW/22, int(titleY)+2)
-	// Draw title in white
-	ebitenutil.DebugPrintAt(screen, title, int(titleX)-textW/2, int(titleY))
+	font.DrawTextWithShadow(screen, title, int(titleX)-textW/2, int(titleY), titleScale, color.White, color.Black, 2)
 
 	// Draw scene selector with background
 	selectorY := height * 0.4
 	selectorBoxW := width * 0.6
-	selectorBoxH := float32(len(s.scenes)*48 + 32)
+	selectorBoxH := float32(len(s.menu)*48 + 32)
 	selectorBoxX := width/2 - selectorBoxW/2
 	selectorBoxY := selectorY - 24
 	vector.DrawFilledRect(screen, selectorBoxX, selectorBoxY, selectorBoxW, selectorBoxH, color.RGBA{0, 0, 0, 160}, false)
 	s.drawSceneSelector(screen, width, height, selectorY)
 
 	// Draw instructions with background
-	instructions := "Use ↑↓ arrows to select, ENTER to start"
+	instructions := fmt.Sprintf("Arrows to select, ENTER to start, Left/Right for volume (%.0f%%)", s.deps.Audio.Volume()*100)
+	instructionsScale := 2
 	instructionsX := width / 2
 	instructionsY := height * 0.8
-	instrBoxW := float32(len(instructions)*12 + 32)
-	instrBoxH := float32(32)
+	instrW, instrH := font.Measure(instructions, instructionsScale)
+	instrBoxW := float32(instrW) + 32
+	instrBoxH := float32(instrH) + 16
 	instrBoxX := instructionsX - instrBoxW/2
 	instrBoxY := instructionsY - 8
 	vector.DrawFilledRect(screen, instrBoxX, instrBoxY, instrBoxW, instrBoxH, color.RGBA{0, 0, 0, 160}, false)
-	ebitenutil.DebugPrintAt(screen, instructions, int(instructionsX)-len(instructions)*6, int(instructionsY))
+	font.DrawText(screen, instructions, int(instructionsX)-instrW/2, int(instructionsY), instructionsScale, color.White)
+
+	s.dirty = false
+}
+
+// scaleForPixelHeight picks the largest integer glyph scale that fits
+// within targetHeight, so callers can ask for an approximate font size
+// (e.g. "36px title") instead of hardcoding a scale factor.
+func scaleForPixelHeight(font *text.Font, targetHeight int) int {
+	_, glyphH := font.GlyphSize()
+	if glyphH == 0 {
+		return 1
+	}
+	scale := targetHeight / glyphH
+	if scale < 1 {
+		scale = 1
+	}
+	return scale
 }
 
+// drawTitleBackground draws the base gradient, then tiles the scrolling
+// title texture on top of it using the frame counter to offset each tile,
+// and finally composites the slower/faster parallax layers over that.
+func (s *StartScene) drawTitleBackground(screen *ebiten.Image, width, height float32) {
+	s.drawGradientBackground(screen, width, height)
+
+	for _, layer := range s.parallax {
+		layer.Draw(screen)
+	}
+
+	if s.bgTile == nil {
+		return
+	}
+
+	tileW := s.bgTile.Bounds().Dx()
+	tileH := s.bgTile.Bounds().Dy()
+	if tileW == 0 || tileH == 0 {
+		return
+	}
+
+	dx := (-s.count / 4) % tileW
+	dy := (s.count / 4) % tileH
+
+	op := &ebiten.DrawImageOptions{}
+	for y := dy - tileH; y < int(height); y += tileH {
+		for x := dx - tileW; x < int(width); x += tileW {
+			op.GeoM.Reset()
+			op.GeoM.Translate(float64(x), float64(y))
+			screen.DrawImage(s.bgTile, op)
+		}
+	}
+}
+
+// drawGradientBackground draws the static base gradient behind the
+// scrolling tile layers.
 func (s *StartScene) drawGradientBackground(screen *ebiten.Image, width, height float32) {
 	topColor := color.RGBA{203, 0, 5, 255}     // Dark blue
 	bottomColor := color.RGBA{80, 20, 10, 255} // Purple
@@ -130,33 +247,36 @@ func (s *StartScene) drawGradientBackground(screen *ebiten.Image, width, height
 	}
 }
 
+const menuFontScale = 2
+
 func (s *StartScene) drawSceneSelector(screen *ebiten.Image, width, height, startY float32) {
 	log.WithFields(log.Fields{
-		"scene_count":      len(s.scenes),
+		"scene_count":      len(s.menu),
 		"selected_index":   s.selectedScene,
 		"start_y_position": startY,
 	}).Trace("Drawing scene selector menu")
 
+	font := s.deps.Assets.Font()
 	spacing := 48
-	for i, scene := range s.scenes {
+	for i, scene := range s.menu {
 		y := startY + float32(i)*float32(spacing)
 		x := width / 2
+		nameW, _ := font.Measure(scene.Name, menuFontScale)
 
 		// Draw selection indicator
 		if i == s.selectedScene {
 			log.WithFields(log.Fields{
-				"selected_scene":    scene.name,
-				"selected_scene_id": scene.id,
+				"selected_scene":    scene.Name,
+				"selected_scene_id": scene.ID,
 				"menu_position":     i,
 			}).Trace("Highlighting selected menu item")
 
 			// Highlight selected item
-			ebitenutil.DebugPrintAt(screen, ">", int(x)-120, int(y))
-			ebitenutil.DebugPrintAt(screen, "<", int(x)+len(scene.name)*12+8, int(y))
+			font.DrawText(screen, ">", int(x)-nameW/2-24, int(y), menuFontScale, color.White)
+			font.DrawText(screen, "<", int(x)+nameW/2+8, int(y), menuFontScale, color.White)
 		}
 		// Draw scene name
-		// (col variable removed, not used)
-		ebitenutil.DebugPrintAt(screen, scene.name, int(x)-len(scene.name)*6, int(y))
+		font.DrawText(screen, scene.Name, int(x)-nameW/2, int(y), menuFontScale, color.White)
 	}
 }
 
@@ -169,34 +289,61 @@ func (s *StartScene) IsLoaded() bool {
 	return s.loaded
 }
 
+// Dirty reports whether the title screen has changed since it was last
+// drawn. Update only flips this on a selection change or a background
+// animation tick, so an idle title screen stops costing a redraw every
+// frame.
+func (s *StartScene) Dirty() bool {
+	return s.dirty
+}
+
 func (s *StartScene) OnEnter() {
 	log.WithFields(log.Fields{
 		"scene":            "StartScene",
-		"available_scenes": len(s.scenes),
+		"available_scenes": len(s.menu),
 		"selected_index":   s.selectedScene,
 	}).Info("Entered title scene")
 
-	if len(s.scenes) > 0 && s.selectedScene < len(s.scenes) {
-		log.WithField("selected_scene", s.scenes[s.selectedScene].name).Debug("Default scene selection")
+	if len(s.menu) > 0 && s.selectedScene < len(s.menu) {
+		log.WithField("selected_scene", s.menu[s.selectedScene].Name).Debug("Default scene selection")
 	}
+
+	s.dirty = true
+	s.deps.Frames.Request()
+	s.deps.Audio.Play("welcome")
 }
 
+// HandleEvent discards every event; StartScene's menu navigation still
+// polls input directly in Update.
+func (s *StartScene) HandleEvent(ev events.Event) {}
+
 func (s *StartScene) OnExit() {
 	log.WithField("scene", "StartScene").Info("Exiting title scene")
 }
 
-func (s *StartScene) Update() SceneId {
+func (s *StartScene) Update() SceneTransition {
+	// Advance the background animation, marking dirty only on the frames
+	// where drawTitleBackground's count/4 offset actually shifts the tile.
+	s.count++
+	if s.count%backgroundTickFrames == 0 {
+		s.dirty = true
+		s.deps.Frames.Request()
+	}
+	for _, layer := range s.parallax {
+		layer.Update()
+	}
+
 	// Safety check to prevent divide by zero
-	if len(s.scenes) == 0 {
+	if len(s.menu) == 0 {
 		log.Warn("No scenes available in title menu, staying on start scene")
-		return StartSceneId
+		return NextScene(StartSceneId, Transition{})
 	}
 
 	// Ensure selectedScene is within bounds
-	if s.selectedScene >= len(s.scenes) {
+	if s.selectedScene >= len(s.menu) {
 		log.WithFields(log.Fields{
 			"current_index": s.selectedScene,
-			"scene_count":   len(s.scenes),
+			"scene_count":   len(s.menu),
 		}).Warn("Selected scene index out of bounds, resetting to 0")
 		s.selectedScene = 0
 	}
@@ -204,42 +351,63 @@ func (s *StartScene) Update() SceneId {
 	// Handle scene selection with arrow keys
 	if inpututil.IsKeyJustPressed(ebiten.KeyUp) {
 		previousSelection := s.selectedScene
-		s.selectedScene = (s.selectedScene - 1 + len(s.scenes)) % len(s.scenes)
+		s.selectedScene = (s.selectedScene - 1 + len(s.menu)) % len(s.menu)
+		s.dirty = true
+		s.deps.Frames.Request()
+		s.deps.Audio.Play("menu_move")
 		log.WithFields(log.Fields{
 			"direction":      "up",
 			"previous_index": previousSelection,
 			"new_index":      s.selectedScene,
-			"selected_scene": s.scenes[s.selectedScene].name,
+			"selected_scene": s.menu[s.selectedScene].Name,
 		}).Debug("Scene selection changed")
 	}
 
 	if inpututil.IsKeyJustPressed(ebiten.KeyDown) {
 		previousSelection := s.selectedScene
-		s.selectedScene = (s.selectedScene + 1) % len(s.scenes)
+		s.selectedScene = (s.selectedScene + 1) % len(s.menu)
+		s.dirty = true
+		s.deps.Frames.Request()
+		s.deps.Audio.Play("menu_move")
 		log.WithFields(log.Fields{
 			"direction":      "down",
 			"previous_index": previousSelection,
 			"new_index":      s.selectedScene,
-			"selected_scene": s.scenes[s.selectedScene].name,
+			"selected_scene": s.menu[s.selectedScene].Name,
 		}).Debug("Scene selection changed")
 	}
 
 	// Handle scene selection with enter
 	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
-		if len(s.scenes) > 0 {
-			selectedScene := s.scenes[s.selectedScene]
+		if len(s.menu) > 0 {
+			selectedScene := s.menu[s.selectedScene]
+			s.deps.Audio.Play("menu_confirm")
 			log.WithFields(log.Fields{
 				"selected_index":      s.selectedScene,
-				"selected_scene_id":   selectedScene.id,
-				"selected_scene_name": selectedScene.name,
+				"selected_scene_id":   selectedScene.ID,
+				"selected_scene_name": selectedScene.Name,
 			}).Info("User selected scene, transitioning")
-			return selectedScene.id
+			return NextScene(selectedScene.ID, Transition{Kind: TransitionCrossfade, Duration: 500 * time.Millisecond})
 		} else {
 			log.Warn("Enter pressed but no scenes available")
 		}
 	}
 
-	return StartSceneId
+	// Master volume slider: Left/Right adjusts, persisted via
+	// AudioRegistry.SetVolume (see common.Config.Audio.Volume).
+	const volumeStep = 0.05
+	if inpututil.IsKeyJustPressed(ebiten.KeyLeft) {
+		s.deps.Audio.DecreaseVolume(volumeStep)
+		s.dirty = true
+		s.deps.Frames.Request()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyRight) {
+		s.deps.Audio.IncreaseVolume(volumeStep)
+		s.dirty = true
+		s.deps.Frames.Request()
+	}
+
+	return NextScene(StartSceneId, Transition{})
 }
 
 var _ Scene = (*StartScene)(nil)
@@ -0,0 +1,38 @@
+package scenes
+
+import "time"
+
+// TransitionKind selects the visual effect SceneManager uses when
+// switching the active scene.
+type TransitionKind int
+
+const (
+	TransitionNone      TransitionKind = iota // instant cut, no effect
+	TransitionFade                            // fade to black, then up on the new scene
+	TransitionCrossfade                       // cross-dissolve between the old and new scene
+	TransitionSlide                           // new scene slides in from the right over the old one
+)
+
+// Transition describes how a scene change should be presented. The zero
+// value is an instant cut, so scenes that don't care about transitions can
+// leave it unset.
+type Transition struct {
+	Kind     TransitionKind
+	Duration time.Duration
+}
+
+// SceneTransition is what Scene.Update returns: which scene to be on next,
+// and - if Next differs from the current scene - how to get there. Build
+// one with NextScene rather than constructing it directly.
+type SceneTransition struct {
+	Next       SceneId
+	Transition Transition
+}
+
+// NextScene builds a SceneTransition requesting scene id via the given
+// transition effect. Scenes that want to stay on their own id can pass
+// Transition{} (or any value, since SceneManager ignores the transition
+// when Next doesn't change the active scene).
+func NextScene(id SceneId, transition Transition) SceneTransition {
+	return SceneTransition{Next: id, Transition: transition}
+}
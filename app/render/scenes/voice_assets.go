@@ -0,0 +1,9 @@
+//go:build !(js && wasm)
+
+package scenes
+
+// registerVoiceClips loads the heavier voice-over assets. It's skipped on
+// js/wasm builds (see voice_assets_web.go) to keep page load size down.
+func registerVoiceClips(reg *AudioRegistry) {
+	reg.Register("welcome", "assets/audio/voice/welcome.ogg")
+}
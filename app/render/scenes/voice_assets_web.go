@@ -0,0 +1,7 @@
+//go:build js && wasm
+
+package scenes
+
+// registerVoiceClips is a no-op on js/wasm builds: voice-over assets are
+// too heavy to ship to the browser, so the web build only gets menu SFX.
+func registerVoiceClips(reg *AudioRegistry) {}
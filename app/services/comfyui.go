@@ -5,14 +5,22 @@ package services
 import (
 	"app/common"
 	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"image"
 	"image/color"
 	"image/png"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
@@ -21,14 +29,22 @@ import (
 )
 
 // ComfyUIService provides methods to interact with the ComfyUI WebSocket API.
-// It manages connections, workflow execution, and image generation through ComfyUI.
+// It manages connections, workflow execution, and image generation through
+// one or more ComfyUI backends (see ComfyUIPool) - every request acquires
+// its own backend and client ID, so concurrent generations never share a
+// WebSocket identity or serialize against each other.
 type ComfyUIService struct {
-	running  bool           // Current running state of the service
-	Config   *common.Config // Application configuration
-	BaseURL  string         // Base URL for ComfyUI API
-	clientID string         // Unique client identifier for WebSocket connections
+	running bool           // Current running state of the service
+	Config  *common.Config // Application configuration
+	Pool    *ComfyUIPool   // Backends this service routes requests across
+
+	healthCheckStop chan struct{} // closed by Stop to end the health-check goroutine started by Start
 }
 
+// healthCheckInterval is how often Start's background goroutine probes
+// every backend in Pool - see ComfyUIBackend.probe.
+const healthCheckInterval = 15 * time.Second
+
 // ImageRatio represents the aspect ratio options for generated images.
 type ImageRatio string
 
@@ -42,13 +58,28 @@ const (
 )
 
 // ImageRequest contains all parameters needed to generate an image through ComfyUI.
+// It's JSON-tagged so JobQueue can persist it as part of a Job's history entry.
 type ImageRequest struct {
-	WorkflowName  string     // Name of the workflow file to use
-	ContentPrompt string     // Text prompt describing the desired image content
-	Seed          int        // Random seed for reproducible generation
-	Steps         int        // Number of diffusion steps for generation
-	Size          int        // Base size for image dimensions
-	Ratio         ImageRatio // Aspect ratio for the generated image
+	WorkflowName   string     `json:"workflow_name"`   // Name of the workflow file to use
+	ContentPrompt  string     `json:"content_prompt"`  // Text prompt describing the desired image content
+	NegativePrompt string     `json:"negative_prompt"` // Text prompt describing what to avoid, if the workflow has a node for it
+	Seed           int        `json:"seed"`            // Random seed for reproducible generation
+	Steps          int        `json:"steps"`           // Number of diffusion steps for generation
+	Size           int        `json:"size"`            // Base size for image dimensions
+	Ratio          ImageRatio `json:"ratio"`           // Aspect ratio for the generated image
+	Sampler        string     `json:"sampler"`         // Sampler name, if the workflow has a node for it
+	CFG            float64    `json:"cfg"`             // Classifier-free guidance scale, if the workflow has a node for it
+
+	// ReferenceImage, if set, is uploaded to ComfyUI (see uploadImage) and
+	// wired into any LoadImage-titled node's image input, for img2img and
+	// style-transfer workflows. Not JSON-tagged - an ebiten.Image can't
+	// round-trip through jobs.json, so a job reloaded from a previous run
+	// (see JobQueue.loadIndex) simply has no reference image.
+	ReferenceImage *ebiten.Image `json:"-"`
+	// Mask, if set, is uploaded the same way and wired into any
+	// LoadImageMask-titled node's image input, with its channel input set
+	// to maskChannel. Same persistence caveat as ReferenceImage.
+	Mask *ebiten.Image `json:"-"`
 }
 
 // ImageResult contains the generated image data returned from ComfyUI.
@@ -63,16 +94,84 @@ type AsyncImageResult struct {
 	Error  error        // Error that occurred during generation (nil if successful)
 }
 
+// AsyncImageProgress reports where a running prompt currently is in
+// workflow execution, built from ComfyUI's executing/progress/
+// execution_start/execution_cached/execution_error websocket messages
+// (see getImages). NodeTitle is resolved from the workflow's own
+// _meta.title via Workflow.TitlesByID, so callers can show e.g.
+// "Sampling: KSampler (12/20)" without re-reading the node graph
+// themselves.
+type AsyncImageProgress struct {
+	PromptID      string
+	CurrentNodeID string
+	NodeTitle     string
+	StepValue     int
+	StepMax       int
+	Cached        bool   // true if CurrentNodeID's output was served from ComfyUI's cache
+	Message       string // human-readable status, e.g. an execution_error's exception message
+}
+
+// AsyncImageEvent is one update from a running image generation job, sent
+// on the channel QueueImageRequest/AsyncNewImageFromPrompt return. Exactly
+// one field is set - Progress for a step update, PartialImage for a
+// preview frame, and Final for the terminal result, which is always the
+// last event sent before the channel closes.
+//
+// PartialImage is declared for callers that want to match on it, but
+// nothing populates it yet: ComfyUI's live preview frames use a different
+// binary message shape than the SaveImageWebsocket output getImages
+// already extracts, and no caller needs it decoded yet.
+type AsyncImageEvent struct {
+	Progress     *AsyncImageProgress
+	PartialImage *ImageResult
+	Final        *AsyncImageResult
+}
+
 // OUTPUT_NODE_WORKFLOW_TYPE defines the ComfyUI node type used for image output.
 const OUTPUT_NODE_WORKFLOW_TYPE = "SaveImageWebsocket"
 
-// WSMessage represents a WebSocket message received from ComfyUI during workflow execution.
+// WSMessage is the first-pass shape of every ComfyUI websocket text
+// message: Type selects which of the wsXxxData structs below Data should
+// be unmarshalled into next - see getImages.
 type WSMessage struct {
-	Type string `json:"type"` // Message type (e.g., "executing")
-	Data struct {
-		PromptID string  `json:"prompt_id"` // Unique identifier for the prompt
-		Node     *string `json:"node"`      // Current executing node ID (nil when done)
-	} `json:"data"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// wsExecutingData is WSMessage.Data for type "executing": ComfyUI sends
+// one of these whenever it starts a new node, and a final one with Node
+// nil when the whole prompt finishes.
+type wsExecutingData struct {
+	PromptID string  `json:"prompt_id"`
+	Node     *string `json:"node"`
+}
+
+// wsProgressData is WSMessage.Data for type "progress": a per-step update
+// for whichever node is currently iterating (typically a sampler).
+type wsProgressData struct {
+	PromptID string `json:"prompt_id"`
+	Node     string `json:"node"`
+	Value    int    `json:"value"`
+	Max      int    `json:"max"`
+}
+
+// wsExecutionStartData is WSMessage.Data for type "execution_start".
+type wsExecutionStartData struct {
+	PromptID string `json:"prompt_id"`
+}
+
+// wsExecutionCachedData is WSMessage.Data for type "execution_cached":
+// Nodes lists the IDs ComfyUI skipped and served from cache.
+type wsExecutionCachedData struct {
+	PromptID string   `json:"prompt_id"`
+	Nodes    []string `json:"nodes"`
+}
+
+// wsExecutionErrorData is WSMessage.Data for type "execution_error".
+type wsExecutionErrorData struct {
+	PromptID         string `json:"prompt_id"`
+	NodeID           string `json:"node_id"`
+	ExceptionMessage string `json:"exception_message"`
 }
 
 // PromptRequest represents the payload sent to ComfyUI to queue a workflow for execution.
@@ -86,30 +185,36 @@ type QueueResponse struct {
 	PromptID string `json:"prompt_id"` // Unique identifier assigned to the queued prompt
 }
 
-// NewComfyUIService creates a new ComfyUI WebSocket API service instance.
-// It initializes the service with the provided configuration and generates a unique client ID.
+// NewComfyUIService creates a new ComfyUI WebSocket API service instance,
+// building its ComfyUIPool from the provided configuration.
 func NewComfyUIService(config *common.Config) *ComfyUIService {
 	return &ComfyUIService{
-		running:  false,
-		Config:   config,
-		BaseURL:  config.ComfyUI.BaseURL,
-		clientID: uuid.New().String(),
+		running: false,
+		Config:  config,
+		Pool:    NewComfyUIPool(config),
 	}
 }
 
-// Start initializes and starts the ComfyUI service.
+// Start initializes and starts the ComfyUI service, including the
+// background goroutine that keeps Pool's backends' health state current.
 // Returns an error if the service fails to start properly.
 func (s *ComfyUIService) Start() error {
 	log.Info("Starting ComfyUI WebSocket API service")
 	s.running = true
+	s.healthCheckStop = make(chan struct{})
+	go s.Pool.RunHealthChecks(healthCheckInterval, s.healthCheckStop)
 	return nil
 }
 
-// Stop gracefully shuts down the ComfyUI service.
-// This method ensures proper cleanup of resources.
+// Stop gracefully shuts down the ComfyUI service, including the
+// health-check goroutine started by Start.
 func (s *ComfyUIService) Stop() {
 	log.Info("Stopping ComfyUI WebSocket API service")
 	s.running = false
+	if s.healthCheckStop != nil {
+		close(s.healthCheckStop)
+		s.healthCheckStop = nil
+	}
 }
 
 // IsRunning returns the current running state of the ComfyUI service.
@@ -118,56 +223,314 @@ func (s *ComfyUIService) IsRunning() bool {
 }
 
 // NewImageFromPrompt generates a new image using the provided ImageRequest parameters.
-// This is the main entry point for custom image generation requests.
-func (s *ComfyUIService) NewImageFromPrompt(request ImageRequest) (*ImageResult, error) {
-	return s.processImageRequest(request)
+// This is the main entry point for custom image generation requests. Cancelling ctx
+// interrupts the prompt server-side and closes the WebSocket instead of waiting out
+// the full generation - see processImageRequest.
+func (s *ComfyUIService) NewImageFromPrompt(ctx context.Context, request ImageRequest) (*ImageResult, error) {
+	return s.processImageRequest(ctx, request, nil, nil)
 }
 
 // NewDefaultImageFromPrompt generates an image using predefined default parameters.
 // This method is useful for testing or when using standard generation settings.
-func (s *ComfyUIService) NewDefaultImageFromPrompt() (*ImageResult, error) {
-	return s.processImageRequest(ImageRequest{
+func (s *ComfyUIService) NewDefaultImageFromPrompt(ctx context.Context) (*ImageResult, error) {
+	return s.processImageRequest(ctx, ImageRequest{
 		WorkflowName:  "default_api.json",
 		ContentPrompt: "A beautiful space station in the sky, seen from the ground",
 		Seed:          42,
 		Steps:         20,
 		Ratio:         ImageRatioPortrait,
 		Size:          512,
-	})
+	}, nil, nil)
 }
 
 // AsyncNewImageFromPrompt generates an image using the provided ImageRequest parameters.
 // This is the main entry point for custom image generation requests. It returns a channel
-// that will receive the image result when it is ready. The channel is closed after sending
-// the result, so it's safe to use with range loops or single reads.
-func (s *ComfyUIService) AsyncNewImageFromPrompt(request ImageRequest) <-chan *AsyncImageResult {
-	ch := make(chan *AsyncImageResult, 1) // Buffered to prevent goroutine leak
+// that receives every AsyncImageEvent as the prompt executes - zero or more Progress
+// events followed by exactly one Final. The channel is closed right after Final, so
+// it's safe to range over. Cancelling ctx interrupts the prompt server-side instead of
+// letting it run to completion unobserved - see processImageRequest.
+func (s *ComfyUIService) AsyncNewImageFromPrompt(ctx context.Context, request ImageRequest) <-chan AsyncImageEvent {
+	return s.QueueImageRequest(ctx, request, nil)
+}
+
+// QueueImageRequest is AsyncNewImageFromPrompt with an extra hook: onQueued,
+// if non-nil, is called with ComfyUI's prompt ID as soon as the prompt is
+// queued (before it starts executing). JobQueue uses this to learn the
+// prompt ID it needs to later Cancel a running job.
+func (s *ComfyUIService) QueueImageRequest(ctx context.Context, request ImageRequest, onQueued func(promptID string)) <-chan AsyncImageEvent {
+	ch := make(chan AsyncImageEvent, 8) // Buffered so a burst of progress events can't stall getImages
 
 	go func() {
 		defer close(ch) // Always close the channel when done
 
-		image, err := s.processImageRequest(request)
+		image, err := s.processImageRequest(ctx, request, onQueued, ch)
 		if err != nil {
-			ch <- &AsyncImageResult{
-				Result: nil,
-				Error:  err,
-			}
+			ch <- AsyncImageEvent{Final: &AsyncImageResult{Error: err}}
 			return
 		}
 
-		ch <- &AsyncImageResult{
-			Result: image,
-			Error:  nil,
-		}
+		ch <- AsyncImageEvent{Final: &AsyncImageResult{Result: image}}
 	}()
 
 	return ch
 }
 
+// Cancel aborts the ComfyUI prompt identified by promptID: it asks
+// whichever backend it was queued on (see ComfyUIPool.TrackPrompt) to
+// interrupt it if it's currently executing, and also asks that backend's
+// queue to drop it in case it hasn't started yet. Either request failing
+// isn't fatal on its own - a prompt can legitimately be in only one of
+// those two states - so errors from both are combined. A promptID this
+// service never tracked (already finished, or from a previous run) is a
+// no-op rather than an error.
+func (s *ComfyUIService) Cancel(promptID string) error {
+	if promptID == "" {
+		return nil
+	}
+
+	backend := s.Pool.BackendForPrompt(promptID)
+	if backend == nil {
+		log.WithField("prompt_id", promptID).Debug("Cancel requested for untracked prompt ID")
+		return nil
+	}
+
+	httpURL := backend.httpBaseURL()
+
+	interruptErr := postJSON(fmt.Sprintf("%s/interrupt", httpURL), nil)
+
+	deleteBody, err := json.Marshal(map[string][]string{"delete": {promptID}})
+	if err != nil {
+		return fmt.Errorf("failed to marshal queue delete request: %w", err)
+	}
+	queueErr := postJSON(fmt.Sprintf("%s/queue", httpURL), deleteBody)
+
+	if interruptErr != nil && queueErr != nil {
+		return fmt.Errorf("failed to cancel prompt %s: interrupt: %v, queue delete: %v", promptID, interruptErr, queueErr)
+	}
+
+	log.WithField("prompt_id", promptID).Info("Cancelled ComfyUI prompt")
+	return nil
+}
+
+// QueueEntry is one entry of GetQueue's running/pending lists. ComfyUI
+// represents each as a [queueNumber, promptID, prompt, extraData,
+// outputsToExecute] tuple - UnmarshalJSON only keeps the first two fields,
+// since those are all any caller of GetQueue needs so far.
+type QueueEntry struct {
+	Number   int
+	PromptID string
+}
+
+func (e *QueueEntry) UnmarshalJSON(data []byte) error {
+	var tuple []json.RawMessage
+	if err := json.Unmarshal(data, &tuple); err != nil {
+		return fmt.Errorf("failed to parse queue entry: %w", err)
+	}
+	if len(tuple) < 2 {
+		return fmt.Errorf("queue entry has %d field(s), want at least 2", len(tuple))
+	}
+	if err := json.Unmarshal(tuple[0], &e.Number); err != nil {
+		return fmt.Errorf("failed to parse queue number: %w", err)
+	}
+	if err := json.Unmarshal(tuple[1], &e.PromptID); err != nil {
+		return fmt.Errorf("failed to parse queue entry prompt ID: %w", err)
+	}
+	return nil
+}
+
+// queueResponse is the shape of a GET /queue response.
+type queueResponse struct {
+	Running []QueueEntry `json:"queue_running"`
+	Pending []QueueEntry `json:"queue_pending"`
+}
+
+// GetQueue returns every prompt currently running or waiting to run,
+// across every backend in the pool.
+func (s *ComfyUIService) GetQueue() (running []QueueEntry, pending []QueueEntry, err error) {
+	for _, backend := range s.Pool.backends {
+		queueURL := fmt.Sprintf("%s/queue", backend.httpBaseURL())
+		resp, err := http.Get(queueURL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to query queue on %s: %w", backend.BaseURL, err)
+		}
+
+		var q queueResponse
+		decodeErr := json.NewDecoder(resp.Body).Decode(&q)
+		resp.Body.Close()
+		if decodeErr != nil {
+			return nil, nil, fmt.Errorf("failed to parse queue response from %s: %w", backend.BaseURL, decodeErr)
+		}
+
+		running = append(running, q.Running...)
+		pending = append(pending, q.Pending...)
+	}
+	return running, pending, nil
+}
+
+// HistoryImage is one output image ComfyUI's /history endpoint reports
+// for a node - its filename on disk, not the image data itself.
+type HistoryImage struct {
+	Filename  string `json:"filename"`
+	Subfolder string `json:"subfolder"`
+	Type      string `json:"type"`
+}
+
+// HistoryNodeOutput is one node's entry in HistoryEntry.Outputs.
+type HistoryNodeOutput struct {
+	Images []HistoryImage `json:"images"`
+}
+
+// HistoryEntry is ComfyUI's /history/{prompt_id} record for one finished
+// prompt: the prompt as submitted plus whatever each node's execution
+// produced. See imageFromHistory, which uses it to recover a generation's
+// result if the WebSocket getImages reads from drops before the image
+// ever streams over it.
+type HistoryEntry struct {
+	Prompt  json.RawMessage              `json:"prompt"`
+	Outputs map[string]HistoryNodeOutput `json:"outputs"`
+	Status  json.RawMessage              `json:"status"`
+}
+
+// GetHistory returns promptID's history entry from whichever backend it
+// was queued on (see ComfyUIPool.TrackPrompt). It errors if promptID
+// isn't tracked by any backend, or ComfyUI has no history for it yet -
+// e.g. because it's still running.
+func (s *ComfyUIService) GetHistory(promptID string) (HistoryEntry, error) {
+	backend := s.Pool.BackendForPrompt(promptID)
+	if backend == nil {
+		return HistoryEntry{}, fmt.Errorf("prompt %s is not tracked by any backend", promptID)
+	}
+
+	historyURL := fmt.Sprintf("%s/history/%s", backend.httpBaseURL(), promptID)
+	resp, err := http.Get(historyURL)
+	if err != nil {
+		return HistoryEntry{}, fmt.Errorf("failed to query history for prompt %s: %w", promptID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return HistoryEntry{}, fmt.Errorf("history request for prompt %s failed with status: %d", promptID, resp.StatusCode)
+	}
+
+	var history map[string]HistoryEntry
+	if err := json.NewDecoder(resp.Body).Decode(&history); err != nil {
+		return HistoryEntry{}, fmt.Errorf("failed to parse history response for prompt %s: %w", promptID, err)
+	}
+
+	entry, ok := history[promptID]
+	if !ok {
+		return HistoryEntry{}, fmt.Errorf("prompt %s has no history entry yet", promptID)
+	}
+	return entry, nil
+}
+
+// imageFromHistory recovers a finished prompt's output image via
+// GetHistory and its backend's /view endpoint, for when getImages'
+// WebSocket drops before the image ever streams over it - ComfyUI still
+// finishes the prompt and writes its result to disk even though the live
+// binary frame never reached us.
+func (s *ComfyUIService) imageFromHistory(backend *ComfyUIBackend, promptID string) (*ebiten.Image, error) {
+	entry, err := s.GetHistory(promptID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, output := range entry.Outputs {
+		for _, img := range output.Images {
+			viewURL := fmt.Sprintf("%s/view?filename=%s&subfolder=%s&type=%s",
+				backend.httpBaseURL(),
+				url.QueryEscape(img.Filename),
+				url.QueryEscape(img.Subfolder),
+				url.QueryEscape(img.Type))
+
+			resp, err := http.Get(viewURL)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch %s from history: %w", img.Filename, err)
+			}
+			decoded, decodeErr := png.Decode(resp.Body)
+			resp.Body.Close()
+			if decodeErr != nil {
+				return nil, fmt.Errorf("failed to decode %s from history: %w", img.Filename, decodeErr)
+			}
+			return ebiten.NewImageFromImage(decoded), nil
+		}
+	}
+	return nil, fmt.Errorf("prompt %s has no output images in history", promptID)
+}
+
+// Interrupt stops whatever prompt is currently executing on every backend
+// in the pool. Unlike Cancel/DeleteQueued, which target one promptID's
+// specific backend, Interrupt has no promptID to look one up by - use
+// Cancel(promptID) instead when the backend is already known.
+func (s *ComfyUIService) Interrupt() error {
+	var errs []string
+	for _, backend := range s.Pool.backends {
+		if err := postJSON(fmt.Sprintf("%s/interrupt", backend.httpBaseURL()), nil); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", backend.BaseURL, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to interrupt %d backend(s): %s", len(errs), strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// DeleteQueued removes promptID from its backend's pending queue before
+// it starts executing, via POST /queue {"delete":[promptID]}. It's the
+// queue-delete half of what Cancel does, exposed standalone for callers
+// that only want to drop a not-yet-started prompt without also sending an
+// /interrupt.
+func (s *ComfyUIService) DeleteQueued(promptID string) error {
+	backend := s.Pool.BackendForPrompt(promptID)
+	if backend == nil {
+		return fmt.Errorf("prompt %s is not tracked by any backend", promptID)
+	}
+
+	body, err := json.Marshal(map[string][]string{"delete": {promptID}})
+	if err != nil {
+		return fmt.Errorf("failed to marshal queue delete request: %w", err)
+	}
+	return postJSON(fmt.Sprintf("%s/queue", backend.httpBaseURL()), body)
+}
+
+// postJSON issues a POST with an optional JSON body, treating anything
+// other than a 2xx response as an error.
+func postJSON(url string, body []byte) error {
+	var reader *bytes.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	} else {
+		reader = bytes.NewReader([]byte{})
+	}
+
+	resp, err := http.Post(url, "application/json", reader)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("request to %s failed with status: %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
 // processImageRequest handles the core logic for image generation requests.
 // It loads the workflow, updates parameters, establishes WebSocket connection,
-// queues the prompt, and retrieves the generated image.
-func (s *ComfyUIService) processImageRequest(request ImageRequest) (*ImageResult, error) {
+// queues the prompt, and retrieves the generated image. onQueued, if non-nil,
+// is called with the assigned prompt ID as soon as it's queued. events, if
+// non-nil, receives each AsyncImageProgress getImages observes while
+// waiting for the result - NewImageFromPrompt/NewDefaultImageFromPrompt
+// pass nil since they only care about the final image. Cancelling ctx while
+// getImages is waiting on the WebSocket interrupts the prompt server-side and
+// closes the socket instead of leaving it to burn GPU time unobserved - see
+// getImages - and processImageRequest returns ctx.Err() instead of a
+// synthesized result.
+func (s *ComfyUIService) processImageRequest(ctx context.Context, request ImageRequest, onQueued func(promptID string), events chan<- AsyncImageEvent) (*ImageResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	log.WithFields(log.Fields{
 		"workflow_name":  request.WorkflowName,
 		"content_prompt": request.ContentPrompt,
@@ -186,19 +549,39 @@ func (s *ComfyUIService) processImageRequest(request ImageRequest) (*ImageResult
 	prompt = s.updatePrompt(prompt, request)
 	log.WithField("prompt_size", len(prompt)).Debug("Updated prompt")
 
+	// Acquire a backend for this request alone - every request gets its
+	// own client ID too, so concurrent generations never share a
+	// WebSocket identity (see ComfyUIPool).
+	backend, err := s.Pool.Acquire(request.WorkflowName)
+	if err != nil {
+		log.WithError(err).Error("No ComfyUI backend available for request")
+		return nil, err
+	}
+	defer s.Pool.Release(backend)
+	clientID := uuid.New().String()
+
+	if request.ReferenceImage != nil || request.Mask != nil {
+		prompt, err = s.applyReferenceImages(backend, prompt, request)
+		if err != nil {
+			log.WithError(err).Error("Failed to upload reference image(s) to ComfyUI")
+			return nil, err
+		}
+	}
+
 	// Open WebSocket connection
-	wsURL := strings.Replace(s.BaseURL, "http://", "ws://", 1)
+	wsURL := strings.Replace(backend.BaseURL, "http://", "ws://", 1)
 	wsURL = strings.Replace(wsURL, "https://", "ws://", 1)
 
 	// Add /ws path like Python version: ws://127.0.0.1:8000/ws?clientId=...
 	if !strings.HasSuffix(wsURL, "/") {
 		wsURL += "/"
 	}
-	wsURL += "ws?clientId=" + s.clientID
+	wsURL += "ws?clientId=" + clientID
 
 	log.WithFields(log.Fields{
 		"websocket_url": wsURL,
-		"client_id":     s.clientID,
+		"client_id":     clientID,
+		"backend":       backend.BaseURL,
 	}).Debug("Attempting WebSocket connection to ComfyUI")
 
 	ws, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
@@ -222,16 +605,36 @@ func (s *ComfyUIService) processImageRequest(request ImageRequest) (*ImageResult
 
 	// Send the workflow to the ComfyUI server
 	log.WithField("prompt_size", len(prompt)).Debug("Queueing prompt to ComfyUI")
-	promptId, err := s.queuePrompt(prompt)
+	promptId, err := s.queuePrompt(backend, clientID, prompt)
 	if err != nil {
 		log.WithError(err).Error("Failed to queue prompt to ComfyUI")
 		return nil, err
 	}
 	log.WithField("prompt_id", promptId).Debug("Prompt queued successfully")
+	s.Pool.TrackPrompt(promptId, backend)
+	defer s.Pool.UntrackPrompt(promptId)
+	if onQueued != nil {
+		onQueued(promptId)
+	}
+
+	// Discover the output node(s) via the GraphAPI instead of assuming a
+	// specific, renumberable node ID (see Workflow.OutputNodes).
+	workflow, err := ParseWorkflow(prompt)
+	if err != nil {
+		log.WithError(err).Error("Failed to parse workflow for graph introspection")
+		return nil, err
+	}
+	outputNodeIDs := make(map[string]bool)
+	for _, node := range workflow.OutputNodes() {
+		outputNodeIDs[node.ID] = true
+	}
+	if len(outputNodeIDs) == 0 {
+		log.Warn("Workflow has no recognized output node; falling back to an empty result")
+	}
 
 	// Get images using the Python function logic
 	log.WithField("prompt_id", promptId).Debug("Starting image retrieval from WebSocket")
-	outputImages := s.getImages(ws, promptId)
+	outputImages := s.getImages(ctx, ws, promptId, workflow.TitlesByID(), outputNodeIDs, events)
 
 	log.WithFields(log.Fields{
 		"output_nodes": len(outputImages),
@@ -284,6 +687,23 @@ func (s *ComfyUIService) processImageRequest(request ImageRequest) (*ImageResult
 		}
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if !imageProcessed {
+		// The WebSocket may have dropped before its binary frame arrived
+		// even though ComfyUI finished the prompt and wrote the image to
+		// disk - poll history for it instead of giving up immediately.
+		if img, err := s.imageFromHistory(backend, promptId); err != nil {
+			log.WithError(err).WithField("prompt_id", promptId).Debug("No recoverable image in ComfyUI history")
+		} else {
+			resultImage = img
+			imageProcessed = true
+			log.WithField("prompt_id", promptId).Info("Recovered ComfyUI image from history after WebSocket read failure")
+		}
+	}
+
 	if !imageProcessed {
 		log.Warn("No images were processed from ComfyUI response, using fallback")
 		// Create fallback white image
@@ -306,13 +726,217 @@ func (s *ComfyUIService) loadPrompt(workflowName string) ([]byte, error) {
 	return workflow, nil
 }
 
-// getImages listens on the WebSocket connection for workflow execution updates
-// and collects generated image data from the output nodes.
-// It follows the same logic as the Python implementation for compatibility.
-func (s *ComfyUIService) getImages(ws *websocket.Conn, promptId string) map[string][][]byte {
+// LoadWorkflowFromPNG decodes the tEXt/iTXt metadata ComfyUI embeds in
+// every image it saves (the standard image/png decoder only returns
+// pixels, so the chunk stream is walked by hand - see pngTextChunks) and
+// returns the embedded API-format workflow, ready to feed into
+// queuePrompt as-is. defaults is an ImageRequest seeded by sniffing the
+// workflow's own Seed/Steps/CFG node values (see imageRequestFromWorkflow),
+// so a caller can show them as editable fields instead of starting from
+// zero. This is what lets an artist iterate in ComfyUI's own editor, drop
+// the winning PNG straight into Config.ComfyUI.WorkflowFolder, and have
+// the engine pick it up with no hand-editing of JSON.
+func (s *ComfyUIService) LoadWorkflowFromPNG(path string) (workflow []byte, defaults ImageRequest, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, ImageRequest{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	chunks, err := pngTextChunks(data)
+	if err != nil {
+		return nil, ImageRequest{}, fmt.Errorf("failed to parse PNG metadata in %s: %w", path, err)
+	}
+
+	promptJSON, ok := chunks["prompt"]
+	if !ok {
+		return nil, ImageRequest{}, fmt.Errorf("%s has no embedded ComfyUI prompt metadata", path)
+	}
+	workflow = []byte(promptJSON)
+
+	parsed, err := ParseWorkflow(workflow)
+	if err != nil {
+		return nil, ImageRequest{}, fmt.Errorf("failed to parse embedded prompt in %s: %w", path, err)
+	}
+
+	// The "workflow" keyword chunk is the editor-format graph (node
+	// positions, UI state) rather than the API format above - nothing in
+	// this service writes back into ComfyUI's own editor today, so
+	// there's nothing to round-trip it into yet. It's only checked for
+	// here so a PNG missing it logs why, instead of failing silently
+	// later if that becomes needed.
+	if _, ok := chunks["workflow"]; !ok {
+		log.WithField("path", path).Debug("PNG has no embedded editor-format workflow chunk")
+	}
+
+	return workflow, imageRequestFromWorkflow(parsed), nil
+}
+
+// imageRequestFromWorkflow sniffs Seed/Steps/CFG node values out of
+// workflow into an ImageRequest, for LoadWorkflowFromPNG's defaults.
+func imageRequestFromWorkflow(workflow Workflow) ImageRequest {
+	var request ImageRequest
+	for _, node := range workflow.NodesByTitle("Seed") {
+		if v, ok := node.Inputs()["value"].(float64); ok {
+			request.Seed = int(v)
+		}
+	}
+	for _, node := range workflow.NodesByTitle("Steps") {
+		if v, ok := node.Inputs()["value"].(float64); ok {
+			request.Steps = int(v)
+		}
+	}
+	for _, node := range workflow.NodesByTitle("CFG") {
+		if v, ok := node.Inputs()["value"].(float64); ok {
+			request.CFG = v
+		}
+	}
+	return request
+}
+
+// pngSignature is the 8-byte magic every PNG file starts with.
+var pngSignature = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+
+// pngTextChunks walks a PNG file's chunk stream by hand, looking for
+// tEXt/iTXt chunks, keyed by keyword - the standard image/png decoder
+// never surfaces these since it only decodes pixels, but ComfyUI embeds
+// its "prompt" (API format) and "workflow" (editor format) metadata this
+// way in every image it saves.
+func pngTextChunks(data []byte) (map[string]string, error) {
+	if len(data) < len(pngSignature) || !bytes.Equal(data[:len(pngSignature)], pngSignature) {
+		return nil, fmt.Errorf("not a PNG file")
+	}
+
+	chunks := make(map[string]string)
+	offset := len(pngSignature)
+	for offset+8 <= len(data) {
+		length := binary.BigEndian.Uint32(data[offset : offset+4])
+		chunkType := string(data[offset+4 : offset+8])
+		dataStart := offset + 8
+		dataEnd := dataStart + int(length)
+		if dataEnd+4 > len(data) {
+			return nil, fmt.Errorf("truncated %s chunk", chunkType)
+		}
+		chunkData := data[dataStart:dataEnd]
+
+		switch chunkType {
+		case "tEXt":
+			if keyword, text, ok := splitNulTerminated(chunkData); ok {
+				chunks[keyword] = text
+			}
+		case "iTXt":
+			keyword, text, err := parseITXtChunk(chunkData)
+			if err != nil {
+				log.WithError(err).Warn("Failed to parse iTXt chunk")
+				break
+			}
+			chunks[keyword] = text
+		case "IEND":
+			return chunks, nil
+		}
+
+		offset = dataEnd + 4
+	}
+	return chunks, nil
+}
+
+// splitNulTerminated splits a tEXt chunk's payload into its
+// NUL-terminated keyword and the text that follows it.
+func splitNulTerminated(data []byte) (keyword, text string, ok bool) {
+	idx := bytes.IndexByte(data, 0)
+	if idx < 0 {
+		return "", "", false
+	}
+	return string(data[:idx]), string(data[idx+1:]), true
+}
+
+// parseITXtChunk splits an iTXt chunk's payload into its keyword and
+// text, per the PNG spec's keyword\0 compressionFlag compressionMethod
+// languageTag\0 translatedKeyword\0 text layout, decompressing text with
+// zlib if compressionFlag says it's compressed.
+func parseITXtChunk(data []byte) (keyword, text string, err error) {
+	idx := bytes.IndexByte(data, 0)
+	if idx < 0 {
+		return "", "", fmt.Errorf("missing keyword terminator")
+	}
+	keyword = string(data[:idx])
+	rest := data[idx+1:]
+
+	if len(rest) < 2 {
+		return "", "", fmt.Errorf("truncated iTXt header")
+	}
+	compressed := rest[0] == 1
+	rest = rest[2:]
+
+	idx = bytes.IndexByte(rest, 0)
+	if idx < 0 {
+		return "", "", fmt.Errorf("missing language tag terminator")
+	}
+	rest = rest[idx+1:]
+
+	idx = bytes.IndexByte(rest, 0)
+	if idx < 0 {
+		return "", "", fmt.Errorf("missing translated keyword terminator")
+	}
+	text = string(rest[idx+1:])
+
+	if !compressed {
+		return keyword, text, nil
+	}
+
+	zr, err := zlib.NewReader(strings.NewReader(text))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open compressed iTXt text: %w", err)
+	}
+	defer zr.Close()
+	decompressed, err := io.ReadAll(zr)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decompress iTXt text: %w", err)
+	}
+	return keyword, string(decompressed), nil
+}
+
+// getImages listens on the WebSocket connection for workflow execution
+// updates and collects generated image data from the output nodes. It
+// follows the same logic as the Python implementation for compatibility.
+// titles resolves a node ID to its workflow title for the progress events
+// sent on events, which may be nil if the caller doesn't want them (see
+// processImageRequest). outputNodeIDs is the set of node IDs
+// Workflow.OutputNodes found - only binary frames sent while one of them
+// is the currently executing node are collected, rather than assuming a
+// single hardcoded output node ID. If ctx is cancelled while the read loop
+// below is blocked waiting on ws, promptId is interrupted/dequeued
+// server-side (see Cancel) and ws is closed to unblock it, rather than
+// leaving the prompt to keep burning GPU time unobserved.
+func (s *ComfyUIService) getImages(ctx context.Context, ws *websocket.Conn, promptId string, titles map[string]string, outputNodeIDs map[string]bool, events chan<- AsyncImageEvent) map[string][][]byte {
 	outputImages := make(map[string][][]byte)
 	currentNode := ""
 
+	stopWatch := make(chan struct{})
+	defer close(stopWatch)
+	go func() {
+		select {
+		case <-ctx.Done():
+			log.WithField("prompt_id", promptId).Info("Context cancelled, interrupting in-flight ComfyUI prompt")
+			if err := s.Cancel(promptId); err != nil {
+				log.WithError(err).WithField("prompt_id", promptId).Warn("Failed to cancel interrupted prompt")
+			}
+			ws.Close()
+		case <-stopWatch:
+		}
+	}()
+
+	emitProgress := func(p AsyncImageProgress) {
+		if events == nil {
+			return
+		}
+		p.PromptID = promptId
+		if p.NodeTitle == "" {
+			p.NodeTitle = titles[p.CurrentNodeID]
+		}
+		events <- AsyncImageEvent{Progress: &p}
+	}
+
+readLoop:
 	for {
 		messageType, messageData, err := ws.ReadMessage()
 		if err != nil {
@@ -320,26 +944,64 @@ func (s *ComfyUIService) getImages(ws *websocket.Conn, promptId string) map[stri
 			break
 		}
 
-		if messageType == websocket.TextMessage {
-			// Handle text message - parse JSON
-			var message WSMessage
-			if err := json.Unmarshal(messageData, &message); err != nil {
+		switch messageType {
+		case websocket.TextMessage:
+			var envelope WSMessage
+			if err := json.Unmarshal(messageData, &envelope); err != nil {
 				log.WithError(err).Debug("Failed to parse websocket message")
 				continue
 			}
 
-			if message.Type == "executing" && message.Data.PromptID == promptId {
-				if message.Data.Node == nil {
+			switch envelope.Type {
+			case "executing":
+				var data wsExecutingData
+				if err := json.Unmarshal(envelope.Data, &data); err != nil || data.PromptID != promptId {
+					continue
+				}
+				if data.Node == nil {
 					// Execution is done
-					break
-				} else {
-					currentNode = *message.Data.Node
+					break readLoop
+				}
+				currentNode = *data.Node
+				emitProgress(AsyncImageProgress{CurrentNodeID: currentNode})
+
+			case "progress":
+				var data wsProgressData
+				if err := json.Unmarshal(envelope.Data, &data); err != nil || (data.PromptID != "" && data.PromptID != promptId) {
+					continue
+				}
+				emitProgress(AsyncImageProgress{CurrentNodeID: data.Node, StepValue: data.Value, StepMax: data.Max})
+
+			case "execution_start":
+				var data wsExecutionStartData
+				if err := json.Unmarshal(envelope.Data, &data); err != nil || data.PromptID != promptId {
+					continue
 				}
+				emitProgress(AsyncImageProgress{Message: "execution started"})
+
+			case "execution_cached":
+				var data wsExecutionCachedData
+				if err := json.Unmarshal(envelope.Data, &data); err != nil || data.PromptID != promptId {
+					continue
+				}
+				emitProgress(AsyncImageProgress{Cached: true, Message: fmt.Sprintf("%d node(s) served from cache", len(data.Nodes))})
+
+			case "execution_error":
+				var data wsExecutionErrorData
+				if err := json.Unmarshal(envelope.Data, &data); err != nil || data.PromptID != promptId {
+					continue
+				}
+				emitProgress(AsyncImageProgress{CurrentNodeID: data.NodeID, Message: data.ExceptionMessage})
+
+			default:
+				// "status" and anything else ComfyUI adds later is
+				// queue-wide, not specific to this prompt - nothing
+				// actionable for a single in-flight request.
 			}
-		} else if messageType == websocket.BinaryMessage {
-			// Handle binary message - collect image data
-			// The currentNode will be the node ID (like "11"), not the class type
-			if currentNode == "11" { // Output node ID
+
+		case websocket.BinaryMessage:
+			// currentNode is a node ID (like "11"), not a class type.
+			if outputNodeIDs[currentNode] {
 				// Skip first 8 bytes (header) like in Python version
 				if len(messageData) > 8 {
 					imageData := messageData[8:]
@@ -354,55 +1016,121 @@ func (s *ComfyUIService) getImages(ws *websocket.Conn, promptId string) map[stri
 	return outputImages
 }
 
-// findNodeByTitle searches through the workflow nodes to find one with the specified title
-// in its _meta field. Returns the node ID and node data if found, empty string and nil otherwise.
-func (s *ComfyUIService) findNodeByTitle(promptMap map[string]interface{}, title string) (string, map[string]interface{}) {
-	for nodeId, nodeInterface := range promptMap {
-		if nodeData, ok := nodeInterface.(map[string]interface{}); ok {
-			if meta, ok := nodeData["_meta"].(map[string]interface{}); ok {
-				if nodeTitle, ok := meta["title"].(string); ok && nodeTitle == title {
-					return nodeId, nodeData
-				}
-			}
+// maskChannel is the channel LoadImageMask nodes read an uploaded mask
+// PNG's transparency from. ComfyUI's node accepts "alpha", "red", "green",
+// or "blue" - alpha is the one that matches a mask authored as an
+// ebiten.Image's alpha channel, the only shape this service uploads.
+const maskChannel = "alpha"
+
+// applyReferenceImages uploads request.ReferenceImage/Mask to backend (see
+// uploadImage) and patches their filenames into any LoadImage/
+// LoadImageMask-titled nodes in prompt, returning the updated workflow.
+func (s *ComfyUIService) applyReferenceImages(backend *ComfyUIBackend, prompt []byte, request ImageRequest) ([]byte, error) {
+	workflow, err := ParseWorkflow(prompt)
+	if err != nil {
+		return prompt, fmt.Errorf("failed to parse prompt for reference images: %w", err)
+	}
+
+	if request.ReferenceImage != nil {
+		filename, err := s.uploadImage(backend, request.ReferenceImage, "reference.png", "", "input")
+		if err != nil {
+			return prompt, fmt.Errorf("failed to upload reference image: %w", err)
 		}
+		workflow.SetInputByTitle("LoadImage", "image", filename)
 	}
-	return "", nil
-}
 
-// updateNodeValue updates the 'value' field in the inputs of a node identified by its title.
-// This method is used to modify workflow parameters before execution.
-// Returns true if the node was found and updated successfully, false otherwise.
-func (s *ComfyUIService) updateNodeValue(promptMap map[string]interface{}, title string, value interface{}) bool {
-	nodeId, nodeData := s.findNodeByTitle(promptMap, title)
-	if nodeData != nil {
-		if inputs, ok := nodeData["inputs"].(map[string]interface{}); ok {
-			inputs["value"] = value
-			log.WithFields(log.Fields{
-				"node_id": nodeId,
-				"title":   title,
-				"value":   value,
-			}).Debug("Updated node value")
-			return true
+	if request.Mask != nil {
+		filename, err := s.uploadImage(backend, request.Mask, "mask.png", "", "mask")
+		if err != nil {
+			return prompt, fmt.Errorf("failed to upload mask image: %w", err)
 		}
+		workflow.SetInputByTitle("LoadImageMask", "image", filename)
+		workflow.SetInputByTitle("LoadImageMask", "channel", maskChannel)
+	}
+
+	updated, err := workflow.Marshal()
+	if err != nil {
+		return prompt, fmt.Errorf("failed to marshal prompt after reference images: %w", err)
 	}
-	log.WithField("title", title).Warn("Could not find node or inputs for title")
-	return false
+	return updated, nil
+}
+
+// uploadImageResponse is ComfyUI's response to a successful /upload/image
+// POST: the name (and location) the file was actually saved under, which
+// may differ from the name we sent if overwrite didn't apply.
+type uploadImageResponse struct {
+	Name      string `json:"name"`
+	Subfolder string `json:"subfolder"`
+	Type      string `json:"type"`
 }
 
-// queuePrompt sends a workflow to ComfyUI for execution via HTTP POST request.
-// It parses the workflow, creates the proper request payload, and returns the prompt ID
-// that can be used to track execution progress.
-func (s *ComfyUIService) queuePrompt(workflow []byte) (string, error) {
+// uploadImage PNG-encodes img and POSTs it to backend's /upload/image
+// endpoint as a multipart form, returning the filename ComfyUI assigned it
+// so a LoadImage/LoadImageMask node's inputs.image can reference it.
+// imgType selects which of ComfyUI's input/temp/mask folders to upload
+// into - "input" for a reference image, "mask" for a mask.
+func (s *ComfyUIService) uploadImage(backend *ComfyUIBackend, img image.Image, name, subfolder, imgType string) (string, error) {
+	var pngData bytes.Buffer
+	if err := png.Encode(&pngData, img); err != nil {
+		return "", fmt.Errorf("failed to encode image for upload: %w", err)
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("image", name)
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload form: %w", err)
+	}
+	if _, err := part.Write(pngData.Bytes()); err != nil {
+		return "", fmt.Errorf("failed to write image data to upload form: %w", err)
+	}
+	_ = writer.WriteField("overwrite", "true")
+	_ = writer.WriteField("subfolder", subfolder)
+	_ = writer.WriteField("type", imgType)
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to finalize upload form: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/upload/image", backend.httpBaseURL())
+	resp, err := http.Post(endpoint, writer.FormDataContentType(), &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload image to %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("upload to %s failed with status: %d", endpoint, resp.StatusCode)
+	}
+
+	var uploadResp uploadImageResponse
+	if err := json.NewDecoder(resp.Body).Decode(&uploadResp); err != nil {
+		return "", fmt.Errorf("failed to parse upload response: %w", err)
+	}
+
+	log.WithFields(log.Fields{
+		"name":    uploadResp.Name,
+		"type":    imgType,
+		"backend": backend.BaseURL,
+	}).Debug("Uploaded image to ComfyUI")
+
+	return uploadResp.Name, nil
+}
+
+// queuePrompt sends a workflow to backend for execution via HTTP POST
+// request, identifying the request with clientID. It parses the workflow,
+// creates the proper request payload, and returns the prompt ID that can
+// be used to track execution progress.
+func (s *ComfyUIService) queuePrompt(backend *ComfyUIBackend, clientID string, workflow []byte) (string, error) {
 	// Parse the workflow JSON
-	var prompt map[string]interface{}
-	if err := json.Unmarshal(workflow, &prompt); err != nil {
+	prompt, err := ParseWorkflow(workflow)
+	if err != nil {
 		return "", fmt.Errorf("failed to parse workflow: %w", err)
 	}
 
 	// Create the request payload like Python version
 	requestPayload := PromptRequest{
-		Prompt:   prompt,
-		ClientID: s.clientID,
+		Prompt:   map[string]interface{}(prompt),
+		ClientID: clientID,
 	}
 
 	// Convert to JSON
@@ -411,10 +1139,7 @@ func (s *ComfyUIService) queuePrompt(workflow []byte) (string, error) {
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	// Extract base URL for HTTP endpoint (convert ws:// to http://)
-	httpURL := strings.Replace(s.BaseURL, "ws://", "http://", 1)
-	httpURL = strings.Replace(httpURL, "/ws", "", 1)
-	endpoint := fmt.Sprintf("%s/prompt", httpURL)
+	endpoint := fmt.Sprintf("%s/prompt", backend.httpBaseURL())
 
 	// Make POST request
 	resp, err := http.Post(endpoint, "application/json", bytes.NewBuffer(jsonData))
@@ -435,7 +1160,8 @@ func (s *ComfyUIService) queuePrompt(workflow []byte) (string, error) {
 
 	log.WithFields(log.Fields{
 		"prompt_id": queueResp.PromptID,
-		"client_id": s.clientID,
+		"client_id": clientID,
+		"backend":   backend.BaseURL,
 	}).Debug("Successfully queued prompt")
 
 	return queueResp.PromptID, nil
@@ -445,27 +1171,36 @@ func (s *ComfyUIService) queuePrompt(workflow []byte) (string, error) {
 // It finds individual nodes by their _meta.title field and updates their input values.
 // Only non-zero/non-empty values are applied to avoid overwriting valid defaults.
 func (s *ComfyUIService) updatePrompt(prompt []byte, request ImageRequest) []byte {
-	var promptMap map[string]interface{}
-	if err := json.Unmarshal(prompt, &promptMap); err != nil {
-		log.WithError(err).Error("Failed to unmarshal prompt")
+	workflow, err := ParseWorkflow(prompt)
+	if err != nil {
+		log.WithError(err).Error("Failed to parse prompt")
 		return prompt
 	}
 
 	// Update individual nodes by their _meta.title
 	if request.Ratio != "" {
-		s.updateNodeValue(promptMap, "Ratio", string(request.Ratio))
+		workflow.SetInputByTitle("Ratio", "value", string(request.Ratio))
 	}
 	if request.ContentPrompt != "" {
-		s.updateNodeValue(promptMap, "ContentPrompt", request.ContentPrompt)
+		workflow.SetInputByTitle("ContentPrompt", "value", request.ContentPrompt)
 	}
 	if request.Seed > 0 {
-		s.updateNodeValue(promptMap, "Seed", request.Seed)
+		workflow.SetInputByTitle("Seed", "value", request.Seed)
 	}
 	if request.Steps > 0 {
-		s.updateNodeValue(promptMap, "Steps", request.Steps)
+		workflow.SetInputByTitle("Steps", "value", request.Steps)
 	}
 	if request.Size > 0 {
-		s.updateNodeValue(promptMap, "Size", float64(request.Size))
+		workflow.SetInputByTitle("Size", "value", float64(request.Size))
+	}
+	if request.NegativePrompt != "" {
+		workflow.SetInputByTitle("NegativePrompt", "value", request.NegativePrompt)
+	}
+	if request.Sampler != "" {
+		workflow.SetInputByTitle("Sampler", "value", request.Sampler)
+	}
+	if request.CFG > 0 {
+		workflow.SetInputByTitle("CFG", "value", request.CFG)
 	}
 
 	// Debug log the updated request
@@ -477,7 +1212,7 @@ func (s *ComfyUIService) updatePrompt(prompt []byte, request ImageRequest) []byt
 		"size":           request.Size,
 	}).Debug("Updated prompt with request values")
 
-	updatedPrompt, err := json.Marshal(promptMap)
+	updatedPrompt, err := workflow.Marshal()
 	if err != nil {
 		log.WithError(err).Error("Failed to marshal updated prompt")
 		return prompt
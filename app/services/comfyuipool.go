@@ -0,0 +1,287 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"app/common"
+)
+
+// SchedulingPolicy selects how ComfyUIPool.Acquire picks a backend among
+// the currently healthy ones.
+type SchedulingPolicy string
+
+const (
+	// LeastBusy sends a request to whichever healthy backend currently has
+	// the fewest in-flight requests. It's the default, since it adapts to
+	// backends with different generation speeds without any tuning.
+	LeastBusy SchedulingPolicy = "least_busy"
+	// RoundRobin cycles through healthy backends in order, ignoring how
+	// busy each one currently is.
+	RoundRobin SchedulingPolicy = "round_robin"
+	// Sticky routes every request for a given workflow name to whichever
+	// backend first served it (falling back to LeastBusy the first time),
+	// so a model already loaded in VRAM keeps serving that workflow
+	// instead of forcing another backend to load it too.
+	Sticky SchedulingPolicy = "sticky"
+)
+
+// parseSchedulingPolicy maps Config.ComfyUI.SchedulingPolicy to a
+// SchedulingPolicy, falling back to LeastBusy for an unset or unrecognized
+// value - the same "unknown falls back to a sane default" convention
+// Config.Comfy.DefaultPreset uses.
+func parseSchedulingPolicy(name string) SchedulingPolicy {
+	switch SchedulingPolicy(name) {
+	case RoundRobin:
+		return RoundRobin
+	case Sticky:
+		return Sticky
+	default:
+		return LeastBusy
+	}
+}
+
+// unhealthyThreshold/unhealthyCooldown govern ComfyUIBackend.probe: a
+// backend is pulled from rotation after unhealthyThreshold consecutive
+// failed probes, and isn't probed again (so it can recover) until
+// unhealthyCooldown has passed since it was marked unhealthy.
+const (
+	unhealthyThreshold = 3
+	unhealthyCooldown  = 30 * time.Second
+)
+
+// ComfyUIBackend is one ComfyUI instance's address and live scheduling
+// state. ComfyUIPool owns a slice of these; callers only ever reach one
+// through Pool.Acquire/Release, never by constructing it directly.
+type ComfyUIBackend struct {
+	BaseURL string
+
+	mu                  sync.Mutex
+	healthy             bool
+	consecutiveFailures int
+	unhealthySince      time.Time
+	inflight            int
+}
+
+// httpBaseURL normalizes BaseURL to an http(s):// REST endpoint, whether
+// it's configured as ws:// or includes a trailing /ws.
+func (b *ComfyUIBackend) httpBaseURL() string {
+	httpURL := strings.Replace(b.BaseURL, "ws://", "http://", 1)
+	httpURL = strings.Replace(httpURL, "/ws", "", 1)
+	return strings.TrimSuffix(httpURL, "/")
+}
+
+func (b *ComfyUIBackend) isHealthy() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.healthy
+}
+
+func (b *ComfyUIBackend) inflightCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.inflight
+}
+
+func (b *ComfyUIBackend) acquire() {
+	b.mu.Lock()
+	b.inflight++
+	b.mu.Unlock()
+}
+
+func (b *ComfyUIBackend) release() {
+	b.mu.Lock()
+	b.inflight--
+	b.mu.Unlock()
+}
+
+// probe checks this backend's /system_stats endpoint and updates its
+// health state. While unhealthy, it skips the check entirely until
+// unhealthyCooldown has passed, so a backend that's actually down isn't
+// hammered with requests every tick.
+func (b *ComfyUIBackend) probe() {
+	b.mu.Lock()
+	skip := !b.healthy && time.Since(b.unhealthySince) < unhealthyCooldown
+	b.mu.Unlock()
+	if skip {
+		return
+	}
+
+	url := fmt.Sprintf("%s/system_stats", b.httpBaseURL())
+	resp, err := http.Get(url)
+	ok := err == nil && resp.StatusCode == http.StatusOK
+	if resp != nil {
+		resp.Body.Close()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ok {
+		if !b.healthy {
+			log.WithField("base_url", b.BaseURL).Info("ComfyUI backend recovered")
+		}
+		b.healthy = true
+		b.consecutiveFailures = 0
+		return
+	}
+
+	b.consecutiveFailures++
+	if b.healthy {
+		if b.consecutiveFailures >= unhealthyThreshold {
+			b.healthy = false
+			b.unhealthySince = time.Now()
+			log.WithFields(log.Fields{"base_url": b.BaseURL, "error": err}).Warn("ComfyUI backend marked unhealthy")
+		}
+	} else {
+		// A recovery attempt after the cooldown failed - wait another
+		// cooldown before trying again.
+		b.unhealthySince = time.Now()
+	}
+}
+
+// ComfyUIPool load-balances image generation requests across one or more
+// ComfyUIBackends, so ComfyUIService can keep generating room art, NPC
+// portraits, and item icons in parallel instead of serializing every
+// request against a single instance. This is the multi-instance routing
+// layer comfy2go's ClientAPI pool uses for the same reason.
+type ComfyUIPool struct {
+	backends []*ComfyUIBackend
+	policy   SchedulingPolicy
+
+	mu     sync.Mutex
+	rrNext int
+	sticky map[string]*ComfyUIBackend // workflow name -> backend, Sticky only
+
+	promptMu      sync.Mutex
+	promptBackend map[string]*ComfyUIBackend // prompt ID -> the backend it was queued on
+}
+
+// NewComfyUIPool builds a pool from config: Config.ComfyUI.Backends if
+// non-empty, otherwise a single backend from the legacy
+// Config.ComfyUI.BaseURL field, so existing single-instance configs keep
+// working unchanged.
+func NewComfyUIPool(config *common.Config) *ComfyUIPool {
+	var backends []*ComfyUIBackend
+	for _, b := range config.ComfyUI.Backends {
+		backends = append(backends, &ComfyUIBackend{BaseURL: b.BaseURL, healthy: true})
+	}
+	if len(backends) == 0 {
+		backends = append(backends, &ComfyUIBackend{BaseURL: config.ComfyUI.BaseURL, healthy: true})
+	}
+
+	return &ComfyUIPool{
+		backends:      backends,
+		policy:        parseSchedulingPolicy(config.ComfyUI.SchedulingPolicy),
+		sticky:        make(map[string]*ComfyUIBackend),
+		promptBackend: make(map[string]*ComfyUIBackend),
+	}
+}
+
+// RunHealthChecks probes every backend once immediately, then again every
+// interval, until stop is closed. It's meant to run in its own goroutine
+// for the service's lifetime - see ComfyUIService.Start/Stop.
+func (p *ComfyUIPool) RunHealthChecks(interval time.Duration, stop <-chan struct{}) {
+	for _, b := range p.backends {
+		b.probe()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			for _, b := range p.backends {
+				b.probe()
+			}
+		}
+	}
+}
+
+// Acquire picks a backend for workflowName per p.policy and marks it busy;
+// the caller must Release it once the request using it finishes, whether
+// it succeeded or not. It only errors if every backend is unhealthy.
+func (p *ComfyUIPool) Acquire(workflowName string) (*ComfyUIBackend, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	healthy := make([]*ComfyUIBackend, 0, len(p.backends))
+	for _, b := range p.backends {
+		if b.isHealthy() {
+			healthy = append(healthy, b)
+		}
+	}
+	if len(healthy) == 0 {
+		return nil, fmt.Errorf("no healthy ComfyUI backends available (%d configured)", len(p.backends))
+	}
+
+	var chosen *ComfyUIBackend
+	switch p.policy {
+	case RoundRobin:
+		chosen = healthy[p.rrNext%len(healthy)]
+		p.rrNext++
+
+	case Sticky:
+		if b, ok := p.sticky[workflowName]; ok && b.isHealthy() {
+			chosen = b
+		} else {
+			chosen = leastBusyBackend(healthy)
+			p.sticky[workflowName] = chosen
+		}
+
+	default:
+		chosen = leastBusyBackend(healthy)
+	}
+
+	chosen.acquire()
+	return chosen, nil
+}
+
+// Release returns backend to the pool after a request using it completes.
+func (p *ComfyUIPool) Release(backend *ComfyUIBackend) {
+	backend.release()
+}
+
+// TrackPrompt records which backend promptID was queued on, so a later
+// Cancel(promptID) (see ComfyUIService.Cancel) can be sent to that backend
+// alone instead of every backend in the pool.
+func (p *ComfyUIPool) TrackPrompt(promptID string, backend *ComfyUIBackend) {
+	p.promptMu.Lock()
+	p.promptBackend[promptID] = backend
+	p.promptMu.Unlock()
+}
+
+// UntrackPrompt forgets promptID's backend once it's no longer cancellable
+// (finished, failed, or already cancelled).
+func (p *ComfyUIPool) UntrackPrompt(promptID string) {
+	p.promptMu.Lock()
+	delete(p.promptBackend, promptID)
+	p.promptMu.Unlock()
+}
+
+// BackendForPrompt returns the backend promptID was queued on, or nil if
+// it's unknown (already untracked, or never tracked).
+func (p *ComfyUIPool) BackendForPrompt(promptID string) *ComfyUIBackend {
+	p.promptMu.Lock()
+	defer p.promptMu.Unlock()
+	return p.promptBackend[promptID]
+}
+
+func leastBusyBackend(backends []*ComfyUIBackend) *ComfyUIBackend {
+	best := backends[0]
+	bestLoad := best.inflightCount()
+	for _, b := range backends[1:] {
+		if load := b.inflightCount(); load < bestLoad {
+			best = b
+			bestLoad = load
+		}
+	}
+	return best
+}
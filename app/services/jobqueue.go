@@ -0,0 +1,350 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hajimehoshi/ebiten/v2"
+	log "github.com/sirupsen/logrus"
+)
+
+// JobStatus is where a Job sits in its lifecycle.
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "pending"
+	JobRunning   JobStatus = "running"
+	JobDone      JobStatus = "done"
+	JobFailed    JobStatus = "failed"
+	JobCancelled JobStatus = "cancelled"
+)
+
+// Job tracks one image-generation request as it moves through a
+// JobQueue. Image only gets decoded for the current process - it isn't
+// part of the persisted index - but it's reloaded from OutputPath for
+// jobs inherited from a previous run (see JobQueue.loadIndex).
+type Job struct {
+	ID         string
+	Request    ImageRequest
+	Status     JobStatus
+	PromptID   string
+	OutputPath string
+	Error      string
+	CreatedAt  time.Time
+	Image      *ebiten.Image
+
+	// Progress is the most recent AsyncImageProgress the worker has seen
+	// for this job, or nil before it starts running. It isn't persisted
+	// to jobs.json - it's only meaningful while the job is actually
+	// executing in this process.
+	Progress *AsyncImageProgress
+
+	// cancel stops the in-flight ComfyUI request - see run, which sets it
+	// once the job starts, and Cancel, which calls it. nil before the job
+	// starts running, so Cancel falls back to its JobPending branch, which
+	// run's own JobCancelled check catches before the request ever starts.
+	cancel context.CancelFunc
+}
+
+// jobIndexEntry is the on-disk shape of a Job in jobs.json: everything
+// except the in-memory decoded image, which is reloaded from OutputPath.
+type jobIndexEntry struct {
+	ID         string       `json:"id"`
+	Request    ImageRequest `json:"request"`
+	Status     JobStatus    `json:"status"`
+	PromptID   string       `json:"prompt_id,omitempty"`
+	OutputPath string       `json:"output_path,omitempty"`
+	Error      string       `json:"error,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+// JobQueue runs ImageRequests through a ComfyUIService one at a time, so
+// callers can enqueue several prompts without blocking on each, and
+// persists a small JSON index of every job under outputDir so the
+// gallery survives restarts.
+type JobQueue struct {
+	service   *ComfyUIService
+	outputDir string
+
+	mu   sync.Mutex
+	jobs []*Job
+
+	pending chan *Job
+}
+
+// NewJobQueue creates a queue bound to service, persisting job metadata
+// and generated images under outputDir. It starts a single background
+// worker that runs jobs one at a time, matching ComfyUI's own
+// single-GPU-worker execution model.
+func NewJobQueue(service *ComfyUIService, outputDir string) *JobQueue {
+	q := &JobQueue{
+		service:   service,
+		outputDir: outputDir,
+		pending:   make(chan *Job, 64),
+	}
+	q.loadIndex()
+	go q.run()
+	return q
+}
+
+// Enqueue adds request to the queue and returns its Job immediately. The
+// job starts at JobPending and is updated in place as it runs, so callers
+// can poll the pointer returned here instead of re-fetching from Jobs().
+func (q *JobQueue) Enqueue(request ImageRequest) *Job {
+	job := &Job{
+		ID:        uuid.New().String(),
+		Request:   request,
+		Status:    JobPending,
+		CreatedAt: time.Now(),
+	}
+
+	q.mu.Lock()
+	q.jobs = append(q.jobs, job)
+	q.mu.Unlock()
+
+	q.saveIndex()
+	q.pending <- job
+	return job
+}
+
+// Jobs returns a snapshot of every known job, oldest first.
+func (q *JobQueue) Jobs() []*Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	out := make([]*Job, len(q.jobs))
+	copy(out, q.jobs)
+	return out
+}
+
+// Cancel aborts job jobID. If it's already running, cancelling its
+// context (see run) interrupts/dequeues the prompt via the service's REST
+// endpoints and closes its WebSocket (see ComfyUIService.getImages);
+// either way the job is marked JobCancelled so the worker goroutine
+// drains its result instead of saving it.
+func (q *JobQueue) Cancel(jobID string) error {
+	job := q.find(jobID)
+	if job == nil {
+		return fmt.Errorf("job %s not found", jobID)
+	}
+
+	q.mu.Lock()
+	runnable := job.Status == JobPending || job.Status == JobRunning
+	if runnable {
+		job.Status = JobCancelled
+	}
+	cancel := job.cancel
+	q.mu.Unlock()
+
+	if !runnable {
+		return nil
+	}
+	q.saveIndex()
+
+	if cancel != nil {
+		cancel()
+	}
+	return nil
+}
+
+func (q *JobQueue) find(jobID string) *Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, j := range q.jobs {
+		if j.ID == jobID {
+			return j
+		}
+	}
+	return nil
+}
+
+func (q *JobQueue) run() {
+	for job := range q.pending {
+		q.mu.Lock()
+		cancelled := job.Status == JobCancelled
+		q.mu.Unlock()
+		if cancelled {
+			continue
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		q.mu.Lock()
+		job.Status = JobRunning
+		job.cancel = cancel
+		q.mu.Unlock()
+		q.saveIndex()
+
+		var result *AsyncImageResult
+		for event := range q.service.QueueImageRequest(ctx, job.Request, func(promptID string) {
+			q.mu.Lock()
+			job.PromptID = promptID
+			q.mu.Unlock()
+			q.saveIndex()
+		}) {
+			if event.Progress != nil {
+				q.mu.Lock()
+				job.Progress = event.Progress
+				q.mu.Unlock()
+			}
+			if event.Final != nil {
+				result = event.Final
+			}
+		}
+		cancel() // release ctx's resources now that the request has finished on its own
+
+		q.mu.Lock()
+		job.Progress = nil
+		job.cancel = nil
+		cancelled = job.Status == JobCancelled
+		q.mu.Unlock()
+		if cancelled {
+			// The request already ran to completion or was interrupted
+			// server-side, but the user dismissed it - drain and move on.
+			log.WithField("job_id", job.ID).Debug("Discarding result for cancelled job")
+			continue
+		}
+
+		if result.Error != nil {
+			q.mu.Lock()
+			job.Status = JobFailed
+			job.Error = result.Error.Error()
+			q.mu.Unlock()
+			log.WithError(result.Error).WithField("job_id", job.ID).Error("Image generation job failed")
+			q.saveIndex()
+			continue
+		}
+
+		outputPath := q.saveImage(job.ID, result.Result.Image)
+
+		q.mu.Lock()
+		job.Image = result.Result.Image
+		job.OutputPath = outputPath
+		job.Status = JobDone
+		q.mu.Unlock()
+		q.saveIndex()
+	}
+}
+
+// saveImage PNG-encodes img under outputDir, named after jobID. It
+// returns the empty string (logging the cause) if the write fails,
+// leaving the job's in-memory Image usable for this session even though
+// it won't survive a restart.
+func (q *JobQueue) saveImage(jobID string, img *ebiten.Image) string {
+	if err := os.MkdirAll(q.outputDir, 0o755); err != nil {
+		log.WithError(err).Error("Failed to create ComfyUI output directory")
+		return ""
+	}
+
+	path := filepath.Join(q.outputDir, jobID+".png")
+	f, err := os.Create(path)
+	if err != nil {
+		log.WithError(err).WithField("path", path).Error("Failed to create output image file")
+		return ""
+	}
+	defer f.Close()
+
+	if err := png.Encode(f, img); err != nil {
+		log.WithError(err).WithField("path", path).Error("Failed to encode output image")
+		return ""
+	}
+	return path
+}
+
+func (q *JobQueue) indexPath() string {
+	return filepath.Join(q.outputDir, "jobs.json")
+}
+
+func (q *JobQueue) saveIndex() {
+	q.mu.Lock()
+	entries := make([]jobIndexEntry, len(q.jobs))
+	for i, j := range q.jobs {
+		entries[i] = jobIndexEntry{
+			ID:         j.ID,
+			Request:    j.Request,
+			Status:     j.Status,
+			PromptID:   j.PromptID,
+			OutputPath: j.OutputPath,
+			Error:      j.Error,
+			CreatedAt:  j.CreatedAt,
+		}
+	}
+	q.mu.Unlock()
+
+	if err := os.MkdirAll(q.outputDir, 0o755); err != nil {
+		log.WithError(err).Error("Failed to create ComfyUI output directory")
+		return
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		log.WithError(err).Error("Failed to marshal job index")
+		return
+	}
+	if err := os.WriteFile(q.indexPath(), data, 0o644); err != nil {
+		log.WithError(err).WithField("path", q.indexPath()).Error("Failed to write job index")
+	}
+}
+
+// loadIndex restores jobs.json from a previous run, if present. Jobs
+// still JobPending/JobRunning when the process last exited are marked
+// JobCancelled rather than resurrected, since nothing is actually
+// generating them anymore.
+func (q *JobQueue) loadIndex() {
+	data, err := os.ReadFile(q.indexPath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.WithError(err).Error("Failed to read job index")
+		}
+		return
+	}
+
+	var entries []jobIndexEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		log.WithError(err).Error("Failed to parse job index")
+		return
+	}
+
+	for _, e := range entries {
+		job := &Job{
+			ID:         e.ID,
+			Request:    e.Request,
+			Status:     e.Status,
+			PromptID:   e.PromptID,
+			OutputPath: e.OutputPath,
+			Error:      e.Error,
+			CreatedAt:  e.CreatedAt,
+		}
+		if job.Status == JobPending || job.Status == JobRunning {
+			job.Status = JobCancelled
+		}
+		if job.Status == JobDone && job.OutputPath != "" {
+			if img, err := loadImageFile(job.OutputPath); err != nil {
+				log.WithError(err).WithField("path", job.OutputPath).Warn("Failed to reload persisted job image")
+			} else {
+				job.Image = img
+			}
+		}
+		q.jobs = append(q.jobs, job)
+	}
+	log.WithField("job_count", len(q.jobs)).Info("Loaded ComfyUI job history from disk")
+}
+
+func loadImageFile(path string) (*ebiten.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	return ebiten.NewImageFromImage(img), nil
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Node is one entry of a Workflow: its ID (ComfyUI's node key - not
+// necessarily numeric or stable across re-saves of a workflow) and its raw
+// fields (class_type, inputs, _meta, ...).
+type Node struct {
+	ID   string
+	Data map[string]interface{}
+}
+
+// ClassType returns the node's class_type field ("KSampler",
+// "SaveImageWebsocket", ...), or "" if missing/malformed.
+func (n Node) ClassType() string {
+	classType, _ := n.Data["class_type"].(string)
+	return classType
+}
+
+// Title returns the node's _meta.title, or "" if missing/malformed.
+func (n Node) Title() string {
+	meta, ok := n.Data["_meta"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	title, _ := meta["title"].(string)
+	return title
+}
+
+// Inputs returns the node's inputs map, creating an empty one in Data if
+// it's absent so SetInput always has somewhere to write.
+func (n Node) Inputs() map[string]interface{} {
+	inputs, ok := n.Data["inputs"].(map[string]interface{})
+	if !ok {
+		inputs = make(map[string]interface{})
+		n.Data["inputs"] = inputs
+	}
+	return inputs
+}
+
+// outputClassTypes lists the ComfyUI node classes Workflow.OutputNodes
+// treats as streaming image bytes back over the websocket. It's a set
+// rather than a single constant since a workflow can legitimately mix in
+// other websocket-output node classes alongside OUTPUT_NODE_WORKFLOW_TYPE.
+var outputClassTypes = map[string]bool{
+	OUTPUT_NODE_WORKFLOW_TYPE: true,
+}
+
+// Workflow wraps a parsed ComfyUI workflow (node ID -> node definition)
+// with the lookups and mutations processImageRequest/updatePrompt need,
+// instead of every caller re-walking a bare map[string]interface{} and
+// assuming specific, renumberable node IDs. This is the GraphAPI layer
+// comfy2go provides over the same JSON shape.
+type Workflow map[string]interface{}
+
+// ParseWorkflow parses raw workflow JSON, as returned by loadPrompt, into
+// a Workflow.
+func ParseWorkflow(raw []byte) (Workflow, error) {
+	var w Workflow
+	if err := json.Unmarshal(raw, &w); err != nil {
+		return nil, fmt.Errorf("failed to parse workflow: %w", err)
+	}
+	return w, nil
+}
+
+// Marshal serializes the workflow back to the JSON shape ComfyUI's
+// /prompt endpoint expects.
+func (w Workflow) Marshal() ([]byte, error) {
+	data, err := json.Marshal(map[string]interface{}(w))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal workflow: %w", err)
+	}
+	return data, nil
+}
+
+// Clone deep-copies the workflow via a JSON round-trip, so SetInput/Link
+// calls against the clone never mutate the original's node maps.
+func (w Workflow) Clone() Workflow {
+	data, err := w.Marshal()
+	if err != nil {
+		log.WithError(err).Error("Failed to marshal workflow for Clone")
+		return Workflow{}
+	}
+	clone, err := ParseWorkflow(data)
+	if err != nil {
+		log.WithError(err).Error("Failed to unmarshal workflow for Clone")
+		return Workflow{}
+	}
+	return clone
+}
+
+func (w Workflow) node(id string) (Node, bool) {
+	data, ok := w[id].(map[string]interface{})
+	if !ok {
+		return Node{}, false
+	}
+	return Node{ID: id, Data: data}, true
+}
+
+// NodesByClassType returns every node whose class_type equals t.
+func (w Workflow) NodesByClassType(t string) []Node {
+	var nodes []Node
+	for id := range w {
+		if node, ok := w.node(id); ok && node.ClassType() == t {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// NodesByTitle returns every node whose _meta.title equals t - usually
+// just one, since updatePrompt assumes titles are unique, but a workflow
+// author can reuse a title across nodes.
+func (w Workflow) NodesByTitle(t string) []Node {
+	var nodes []Node
+	for id := range w {
+		if node, ok := w.node(id); ok && node.Title() == t {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// OutputNodes returns every node whose class_type is one ComfyUI streams
+// image bytes back for over the websocket (see getImages), so callers
+// don't need to assume a specific, renumberable node ID like the old
+// hardcoded "11" did.
+func (w Workflow) OutputNodes() []Node {
+	var nodes []Node
+	for id := range w {
+		if node, ok := w.node(id); ok && outputClassTypes[node.ClassType()] {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// TitlesByID maps every node ID that has a _meta.title to that title, for
+// resolving a bare node ID (as getImages sees it over the websocket) back
+// to something a caller can show the player.
+func (w Workflow) TitlesByID() map[string]string {
+	titles := make(map[string]string)
+	for id := range w {
+		if node, ok := w.node(id); ok {
+			if title := node.Title(); title != "" {
+				titles[id] = title
+			}
+		}
+	}
+	return titles
+}
+
+// SetInput sets inputName in nodeID's inputs to v. It's a no-op if nodeID
+// doesn't exist in the workflow.
+func (w Workflow) SetInput(nodeID, inputName string, v interface{}) {
+	node, ok := w.node(nodeID)
+	if !ok {
+		log.WithField("node_id", nodeID).Warn("SetInput: node not found")
+		return
+	}
+	node.Inputs()[inputName] = v
+}
+
+// SetInputByTitle finds a node by its _meta.title and sets inputName on
+// it, returning true if a node was found. A workflow with no node by that
+// title is a no-op - see updatePrompt, which applies each ImageRequest
+// field this way and simply skips those the active workflow doesn't
+// define a node for.
+func (w Workflow) SetInputByTitle(title, inputName string, v interface{}) bool {
+	nodes := w.NodesByTitle(title)
+	if len(nodes) == 0 {
+		log.WithFields(log.Fields{"title": title, "input": inputName}).Warn("Could not find node for title")
+		return false
+	}
+	w.SetInput(nodes[0].ID, inputName, v)
+	return true
+}
+
+// Link wires toNode's toInput to read from fromNode's fromSlot output,
+// using ComfyUI's [nodeID, slotIndex] link encoding - the same shape
+// ComfyUI itself writes when a connection is dragged between two nodes in
+// its own editor.
+func (w Workflow) Link(fromNode string, fromSlot int, toNode, toInput string) {
+	node, ok := w.node(toNode)
+	if !ok {
+		log.WithField("node_id", toNode).Warn("Link: target node not found")
+		return
+	}
+	node.Inputs()[toInput] = []interface{}{fromNode, fromSlot}
+}
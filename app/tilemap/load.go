@@ -0,0 +1,194 @@
+package tilemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// xmlMap mirrors the subset of Tiled's .tmx schema this package
+// understands: map size, tileset references, CSV-encoded tile layers, and
+// object groups. Anything else in the file is silently ignored by
+// encoding/xml.
+type xmlMap struct {
+	XMLName      xml.Name         `xml:"map"`
+	Width        int              `xml:"width,attr"`
+	Height       int              `xml:"height,attr"`
+	TileWidth    int              `xml:"tilewidth,attr"`
+	TileHeight   int              `xml:"tileheight,attr"`
+	Tilesets     []xmlTilesetRef  `xml:"tileset"`
+	Layers       []xmlLayer       `xml:"layer"`
+	ObjectGroups []xmlObjectGroup `xml:"objectgroup"`
+}
+
+// xmlTilesetRef is a <tileset> element as it appears inside a .tmx: either
+// a reference to an external .tsx file (Source set) or a full inline
+// definition (the same shape a standalone .tsx root element has).
+type xmlTilesetRef struct {
+	FirstGID int    `xml:"firstgid,attr"`
+	Source   string `xml:"source,attr"`
+	xmlTileset
+}
+
+// xmlTileset is a <tileset> element's own attributes/children, shared by
+// both the inline form above and a standalone .tsx document's root.
+type xmlTileset struct {
+	Name       string   `xml:"name,attr"`
+	TileWidth  int      `xml:"tilewidth,attr"`
+	TileHeight int      `xml:"tileheight,attr"`
+	TileCount  int      `xml:"tilecount,attr"`
+	Columns    int      `xml:"columns,attr"`
+	Image      xmlImage `xml:"image"`
+}
+
+type xmlImage struct {
+	Source string `xml:"source,attr"`
+}
+
+type xmlLayer struct {
+	Name   string  `xml:"name,attr"`
+	Width  int     `xml:"width,attr"`
+	Height int     `xml:"height,attr"`
+	Data   xmlData `xml:"data"`
+}
+
+type xmlData struct {
+	Encoding string `xml:"encoding,attr"`
+	CharData string `xml:",chardata"`
+}
+
+type xmlObjectGroup struct {
+	Name    string      `xml:"name,attr"`
+	Objects []xmlObject `xml:"object"`
+}
+
+type xmlObject struct {
+	ID     int     `xml:"id,attr"`
+	Name   string  `xml:"name,attr"`
+	Type   string  `xml:"type,attr"`
+	X      float64 `xml:"x,attr"`
+	Y      float64 `xml:"y,attr"`
+	Width  float64 `xml:"width,attr"`
+	Height float64 `xml:"height,attr"`
+}
+
+// LoadMap parses the .tmx file at path. External tileset references
+// (<tileset source="...">) are resolved relative to path's directory via
+// LoadTileset; inline tilesets have their image path resolved the same
+// way.
+func LoadMap(path string) (*Map, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("tilemap: reading map %q: %w", path, err)
+	}
+
+	var raw xmlMap
+	if err := xml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("tilemap: parsing map %q: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	m := &Map{
+		Width:      raw.Width,
+		Height:     raw.Height,
+		TileWidth:  raw.TileWidth,
+		TileHeight: raw.TileHeight,
+	}
+
+	for _, ref := range raw.Tilesets {
+		if ref.Source != "" {
+			ts, err := LoadTileset(filepath.Join(dir, ref.Source))
+			if err != nil {
+				return nil, err
+			}
+			ts.FirstGID = ref.FirstGID
+			m.Tilesets = append(m.Tilesets, *ts)
+			continue
+		}
+		m.Tilesets = append(m.Tilesets, tilesetFromXML(ref.xmlTileset, ref.FirstGID, dir))
+	}
+
+	for _, l := range raw.Layers {
+		tiles, err := parseCSVTiles(l.Data.CharData)
+		if err != nil {
+			return nil, fmt.Errorf("tilemap: layer %q: %w", l.Name, err)
+		}
+		m.Layers = append(m.Layers, Layer{
+			Name:   l.Name,
+			Width:  l.Width,
+			Height: l.Height,
+			Tiles:  tiles,
+		})
+	}
+
+	for _, og := range raw.ObjectGroups {
+		group := ObjectGroup{Name: og.Name}
+		for _, o := range og.Objects {
+			group.Objects = append(group.Objects, Object{
+				ID:     o.ID,
+				Name:   o.Name,
+				Type:   o.Type,
+				X:      o.X,
+				Y:      o.Y,
+				Width:  o.Width,
+				Height: o.Height,
+			})
+		}
+		m.ObjectGroups = append(m.ObjectGroups, group)
+	}
+
+	return m, nil
+}
+
+// LoadTileset parses a standalone .tsx file at path. The tileset's image
+// path is resolved relative to path's directory. FirstGID isn't known
+// from a .tsx file alone - callers that got here via a .tmx's <tileset
+// source="..."> reference should set it from that reference's firstgid
+// attribute.
+func LoadTileset(path string) (*Tileset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("tilemap: reading tileset %q: %w", path, err)
+	}
+
+	var raw xmlTileset
+	if err := xml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("tilemap: parsing tileset %q: %w", path, err)
+	}
+
+	ts := tilesetFromXML(raw, 0, filepath.Dir(path))
+	return &ts, nil
+}
+
+func tilesetFromXML(raw xmlTileset, firstGID int, dir string) Tileset {
+	return Tileset{
+		FirstGID:    firstGID,
+		Name:        raw.Name,
+		TileWidth:   raw.TileWidth,
+		TileHeight:  raw.TileHeight,
+		TileCount:   raw.TileCount,
+		Columns:     raw.Columns,
+		ImageSource: filepath.Join(dir, raw.Image.Source),
+	}
+}
+
+// parseCSVTiles parses a <data encoding="csv"> element's text content
+// into GIDs, tolerating the whitespace/newlines Tiled pads it with for
+// readability.
+func parseCSVTiles(raw string) ([]int, error) {
+	fields := strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r' || r == ' ' || r == '\t'
+	})
+	tiles := make([]int, 0, len(fields))
+	for _, field := range fields {
+		gid, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tile GID %q: %w", field, err)
+		}
+		tiles = append(tiles, gid)
+	}
+	return tiles, nil
+}
@@ -0,0 +1,108 @@
+// Package tilemap parses Tiled (mapeditor.org) .tmx maps and .tsx
+// tilesets into plain Go types, independent of any rendering engine.
+// DungeonScene (see app/render/scenes) is the current consumer: it turns
+// Tileset.ImageSource + Layer tile GIDs into sub-images to draw, and
+// ObjectGroup rectangles into collidable walls/hazards/spawns.
+package tilemap
+
+// Map is a parsed .tmx document: its tile grid size, one or more tile
+// Layers, the Tilesets supplying their tile images, and any ObjectGroups
+// (collision, hazards, spawns, ...) authored alongside them.
+type Map struct {
+	Width, Height         int // map size, in tiles
+	TileWidth, TileHeight int // tile size, in pixels
+
+	Tilesets     []Tileset
+	Layers       []Layer
+	ObjectGroups []ObjectGroup
+}
+
+// Tileset describes one image sliced into tiles, and the range of global
+// tile IDs (GIDs) it supplies starting at FirstGID. A Map's Layer tile
+// values are GIDs into whichever Tileset's [FirstGID, FirstGID+TileCount)
+// range contains them - see Map.TilesetForGID.
+type Tileset struct {
+	FirstGID              int
+	Name                  string
+	TileWidth, TileHeight int
+	TileCount             int
+	Columns               int
+
+	// ImageSource is the tileset image's path, resolved relative to
+	// whichever .tmx/.tsx file declared it (see LoadMap/LoadTileset), so
+	// callers can pass it straight to an image loader.
+	ImageSource string
+}
+
+// Layer is one tile layer: a row-major grid of GIDs, Width*Height long.
+// A GID of 0 means "no tile" at that cell.
+type Layer struct {
+	Name          string
+	Width, Height int
+	Tiles         []int
+}
+
+// GIDAt returns the tile GID at tile coordinates (tx, ty), or 0 (empty)
+// if they're outside the layer's bounds.
+func (l *Layer) GIDAt(tx, ty int) int {
+	if tx < 0 || ty < 0 || tx >= l.Width || ty >= l.Height {
+		return 0
+	}
+	return l.Tiles[ty*l.Width+tx]
+}
+
+// ObjectGroup is one object layer: a named collection of free-form
+// rectangles (walls, hazards, spawn points, ...). DungeonScene sorts
+// these by Name rather than the tilemap package prescribing any meaning
+// for them.
+type ObjectGroup struct {
+	Name    string
+	Objects []Object
+}
+
+// Object is a single rectangle from an object layer, in pixel
+// coordinates. Point-like objects (spawns) are conventionally given
+// Width=Height=0.
+type Object struct {
+	ID     int
+	Name   string
+	Type   string
+	X, Y   float64
+	Width  float64
+	Height float64
+}
+
+// TilesetForGID returns the Tileset containing gid and gid's local tile
+// index within it (gid - Tileset.FirstGID). ok is false for the empty
+// GID (0) or a GID no loaded Tileset covers.
+func (m *Map) TilesetForGID(gid int) (ts Tileset, localIndex int, ok bool) {
+	if gid == 0 {
+		return Tileset{}, 0, false
+	}
+	best := -1
+	for i, t := range m.Tilesets {
+		if t.FirstGID <= gid && (best == -1 || t.FirstGID > m.Tilesets[best].FirstGID) {
+			best = i
+		}
+	}
+	if best == -1 {
+		return Tileset{}, 0, false
+	}
+	return m.Tilesets[best], gid - m.Tilesets[best].FirstGID, true
+}
+
+// PixelWidth and PixelHeight return the map's total size in pixels.
+func (m *Map) PixelWidth() float64  { return float64(m.Width * m.TileWidth) }
+func (m *Map) PixelHeight() float64 { return float64(m.Height * m.TileHeight) }
+
+// ObjectGroupNamed returns the first ObjectGroup whose Name matches name
+// (case-sensitive, matching Tiled's own object layer names), and whether
+// one was found.
+func (m *Map) ObjectGroupNamed(name string) (ObjectGroup, bool) {
+	for _, group := range m.ObjectGroups {
+		if group.Name == name {
+			return group, true
+		}
+	}
+	return ObjectGroup{}, false
+}
@@ -0,0 +1,431 @@
+// Package ui holds small, reusable input widgets shared across scenes,
+// starting with TextInput: a font-rendered prompt box with caret
+// movement, shift-selection, and word-wrapped multi-line text.
+//
+// Known limitations: copy/paste only round-trips within the game itself,
+// not with the OS clipboard (see the clipboard var below), and there's no
+// IME support, so composing CJK/other multi-keystroke input doesn't work
+// - ebiten.AppendInputChars only ever delivers it as a sequence of
+// already-committed runes, one at a time. Both are acceptable for this
+// widget's current uses (prompt boxes, server addresses) but would need
+// addressing before using TextInput for anything that needs real
+// interop or non-Latin input.
+package ui
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"app/render/scenes/text"
+)
+
+// clipboard is an in-process Ctrl+C/Ctrl+X/Ctrl+V buffer shared by every
+// TextInput. This module has no way to fetch an OS clipboard package (no
+// network access to vendor golang.design/x/clipboard into go.sum), so
+// copy/paste only round-trips within the game itself; swapping in a real
+// OS clipboard later is just a matter of backing these two spots with it
+// instead of a package-level string.
+var clipboard string
+
+// caretBlinkInterval is how often an active, idle TextInput toggles its
+// caret, matching the blink rate ComfyUIScene used before this widget.
+const caretBlinkInterval = 500 * time.Millisecond
+
+// TextInputConfig configures a new TextInput. Font and Scale are required;
+// the rest are optional.
+type TextInputConfig struct {
+	Font  *text.Font
+	Scale int
+
+	// MaxWidth word-wraps the displayed text at this pixel width. Zero
+	// disables wrapping (the line just runs past the box).
+	MaxWidth int
+	// Multiline allows Shift+Enter to insert a newline instead of
+	// submitting. Plain Enter always submits.
+	Multiline bool
+	// Placeholder is shown, dimmed, whenever the field is empty.
+	Placeholder string
+
+	// OnDirty, if set, is called whenever the widget's visible state
+	// changes (keystroke, selection, caret blink) so the owning scene can
+	// request a redraw without polling the widget every frame.
+	OnDirty func()
+}
+
+// TextInput is a caret-and-selection-aware text box rendered with a
+// bitmap Font. It owns no screen position - callers pass x, y to Draw -
+// and reports completion through Submitted/Cancelled channels rather than
+// a callback, the same way Scene.Update reports transitions by value.
+type TextInput struct {
+	cfg TextInputConfig
+
+	active    bool
+	value     []rune
+	caret     int
+	selAnchor int // -1 when nothing is selected
+
+	cursorVisible bool
+	lastBlink     time.Time
+
+	submit chan string
+	cancel chan struct{}
+}
+
+// NewTextInput creates an inactive, empty TextInput from cfg.
+func NewTextInput(cfg TextInputConfig) *TextInput {
+	if cfg.Scale < 1 {
+		cfg.Scale = 1
+	}
+	return &TextInput{
+		cfg:           cfg,
+		selAnchor:     -1,
+		cursorVisible: true,
+		lastBlink:     time.Now(),
+		submit:        make(chan string, 1),
+		cancel:        make(chan struct{}, 1),
+	}
+}
+
+// Text returns the current contents.
+func (t *TextInput) Text() string {
+	return string(t.value)
+}
+
+// SetText replaces the contents and moves the caret to the end.
+func (t *TextInput) SetText(s string) {
+	t.value = []rune(s)
+	t.caret = len(t.value)
+	t.selAnchor = -1
+	t.markDirty()
+}
+
+// Active reports whether the field is currently receiving input.
+func (t *TextInput) Active() bool {
+	return t.active
+}
+
+// Activate starts accepting keyboard input.
+func (t *TextInput) Activate() {
+	t.active = true
+	t.cursorVisible = true
+	t.lastBlink = time.Now()
+	t.markDirty()
+}
+
+// Deactivate stops accepting keyboard input without emitting an event,
+// e.g. when the owning scene loses focus for an unrelated reason.
+func (t *TextInput) Deactivate() {
+	t.active = false
+	t.selAnchor = -1
+	t.markDirty()
+}
+
+// Submitted delivers the field's text each time Enter commits it.
+func (t *TextInput) Submitted() <-chan string {
+	return t.submit
+}
+
+// Cancelled fires each time Escape dismisses the field without submitting.
+func (t *TextInput) Cancelled() <-chan struct{} {
+	return t.cancel
+}
+
+// Update processes keyboard input. It's a no-op while inactive, so the
+// owning scene can call it unconditionally every tick.
+func (t *TextInput) Update() {
+	if !t.active {
+		return
+	}
+
+	if time.Since(t.lastBlink) > caretBlinkInterval {
+		t.cursorVisible = !t.cursorVisible
+		t.lastBlink = time.Now()
+		t.markDirty()
+	}
+
+	shift := ebiten.IsKeyPressed(ebiten.KeyShiftLeft) || ebiten.IsKeyPressed(ebiten.KeyShiftRight)
+	ctrl := ebiten.IsKeyPressed(ebiten.KeyControlLeft) || ebiten.IsKeyPressed(ebiten.KeyControlRight) ||
+		ebiten.IsKeyPressed(ebiten.KeyMetaLeft) || ebiten.IsKeyPressed(ebiten.KeyMetaRight)
+
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		t.active = false
+		t.selAnchor = -1
+		select {
+		case t.cancel <- struct{}{}:
+		default:
+		}
+		t.markDirty()
+		return
+
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		if t.cfg.Multiline && shift {
+			t.insert("\n")
+			break
+		}
+		t.active = false
+		result := t.Text()
+		t.value = nil
+		t.caret, t.selAnchor = 0, -1
+		select {
+		case t.submit <- result:
+		default:
+		}
+		t.markDirty()
+		return
+
+	case ctrl && inpututil.IsKeyJustPressed(ebiten.KeyA):
+		t.selAnchor, t.caret = 0, len(t.value)
+		t.markDirty()
+	case ctrl && inpututil.IsKeyJustPressed(ebiten.KeyC):
+		t.copySelection()
+	case ctrl && inpututil.IsKeyJustPressed(ebiten.KeyX):
+		t.copySelection()
+		t.deleteSelection()
+	case ctrl && inpututil.IsKeyJustPressed(ebiten.KeyV):
+		t.insert(clipboard)
+
+	case inpututil.IsKeyJustPressed(ebiten.KeyLeft):
+		t.moveCaretTo(t.caret-1, shift)
+	case inpututil.IsKeyJustPressed(ebiten.KeyRight):
+		t.moveCaretTo(t.caret+1, shift)
+	case inpututil.IsKeyJustPressed(ebiten.KeyHome):
+		t.moveCaretTo(t.lineStart(t.caret), shift)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnd):
+		t.moveCaretTo(t.lineEnd(t.caret), shift)
+
+	case inpututil.IsKeyJustPressed(ebiten.KeyBackspace):
+		if t.hasSelection() {
+			t.deleteSelection()
+		} else if t.caret > 0 {
+			t.value = append(t.value[:t.caret-1], t.value[t.caret:]...)
+			t.caret--
+		}
+		t.markDirty()
+	case inpututil.IsKeyJustPressed(ebiten.KeyDelete):
+		if t.hasSelection() {
+			t.deleteSelection()
+		} else if t.caret < len(t.value) {
+			t.value = append(t.value[:t.caret], t.value[t.caret+1:]...)
+		}
+		t.markDirty()
+	}
+
+	if chars := ebiten.AppendInputChars(nil); len(chars) > 0 {
+		t.insert(string(chars))
+	}
+}
+
+func (t *TextInput) markDirty() {
+	if t.cfg.OnDirty != nil {
+		t.cfg.OnDirty()
+	}
+}
+
+func (t *TextInput) hasSelection() bool {
+	return t.selAnchor >= 0 && t.selAnchor != t.caret
+}
+
+func (t *TextInput) selectionRange() (lo, hi int) {
+	if t.selAnchor < t.caret {
+		return t.selAnchor, t.caret
+	}
+	return t.caret, t.selAnchor
+}
+
+func (t *TextInput) deleteSelection() {
+	if !t.hasSelection() {
+		return
+	}
+	lo, hi := t.selectionRange()
+	t.value = append(t.value[:lo], t.value[hi:]...)
+	t.caret, t.selAnchor = lo, -1
+	t.markDirty()
+}
+
+func (t *TextInput) copySelection() {
+	if !t.hasSelection() {
+		return
+	}
+	lo, hi := t.selectionRange()
+	clipboard = string(t.value[lo:hi])
+}
+
+func (t *TextInput) insert(s string) {
+	if s == "" {
+		return
+	}
+	if t.hasSelection() {
+		t.deleteSelection()
+	}
+	runes := []rune(s)
+	merged := make([]rune, 0, len(t.value)+len(runes))
+	merged = append(merged, t.value[:t.caret]...)
+	merged = append(merged, runes...)
+	merged = append(merged, t.value[t.caret:]...)
+	t.value = merged
+	t.caret += len(runes)
+	t.markDirty()
+}
+
+func (t *TextInput) moveCaretTo(pos int, extend bool) {
+	if pos < 0 {
+		pos = 0
+	}
+	if pos > len(t.value) {
+		pos = len(t.value)
+	}
+	if extend {
+		if t.selAnchor < 0 {
+			t.selAnchor = t.caret
+		}
+	} else {
+		t.selAnchor = -1
+	}
+	t.caret = pos
+	t.markDirty()
+}
+
+func (t *TextInput) lineStart(pos int) int {
+	for i := pos - 1; i >= 0; i-- {
+		if t.value[i] == '\n' {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func (t *TextInput) lineEnd(pos int) int {
+	for i := pos; i < len(t.value); i++ {
+		if t.value[i] == '\n' {
+			return i
+		}
+	}
+	return len(t.value)
+}
+
+// span is a rune-index range [start, end) of t.value rendered as one
+// visual line, either a real newline-delimited paragraph or a wrap point
+// inserted by wrapParagraph.
+type span struct {
+	start, end int
+}
+
+func (t *TextInput) paragraphs() []span {
+	var lines []span
+	start := 0
+	for i, r := range t.value {
+		if r == '\n' {
+			lines = append(lines, span{start, i})
+			start = i + 1
+		}
+	}
+	return append(lines, span{start, len(t.value)})
+}
+
+// wrapLines breaks each paragraph at word boundaries so no line exceeds
+// cfg.MaxWidth, in addition to the explicit newlines Shift+Enter inserts.
+// A single word longer than MaxWidth is left to overflow rather than
+// broken mid-word.
+func (t *TextInput) wrapLines() []span {
+	paragraphs := t.paragraphs()
+	if t.cfg.MaxWidth <= 0 {
+		return paragraphs
+	}
+
+	var lines []span
+	for _, p := range paragraphs {
+		lines = append(lines, t.wrapParagraph(p)...)
+	}
+	return lines
+}
+
+func (t *TextInput) wrapParagraph(p span) []span {
+	if p.start == p.end {
+		return []span{p}
+	}
+
+	var lines []span
+	lineStart, wordStart := p.start, p.start
+	for i := p.start; i <= p.end; i++ {
+		if i < p.end && t.value[i] != ' ' {
+			continue
+		}
+
+		if lineStart < wordStart {
+			if w, _ := t.cfg.Font.Measure(string(t.value[lineStart:i]), t.cfg.Scale); w > t.cfg.MaxWidth {
+				lines = append(lines, span{lineStart, wordStart - 1})
+				lineStart = wordStart
+			}
+		}
+		wordStart = i + 1
+	}
+	lines = append(lines, span{lineStart, p.end})
+	return lines
+}
+
+// Draw renders the field's text, selection highlight, and (if active and
+// currently visible) caret with its top-left corner at (x, y).
+func (t *TextInput) Draw(screen *ebiten.Image, x, y int) {
+	f := t.cfg.Font
+	gw, gh := f.GlyphSize()
+	lineHeight := gh * t.cfg.Scale
+
+	if len(t.value) == 0 && t.cfg.Placeholder != "" {
+		f.DrawText(screen, t.cfg.Placeholder, x, y, t.cfg.Scale, color.RGBA{160, 160, 170, 255})
+		if t.active && t.cursorVisible {
+			vector.DrawFilledRect(screen, float32(x), float32(y), 2, float32(lineHeight), color.White, false)
+		}
+		return
+	}
+
+	lines := t.wrapLines()
+	selecting := t.hasSelection()
+	var selLo, selHi int
+	if selecting {
+		selLo, selHi = t.selectionRange()
+	}
+
+	caretLine, caretCol := -1, 0
+	for li, ln := range lines {
+		if caretLine == -1 && t.caret >= ln.start && t.caret <= ln.end {
+			caretLine, caretCol = li, t.caret-ln.start
+		}
+
+		lineY := y + li*lineHeight
+
+		if selecting {
+			lo, hi := selLo, selHi
+			if lo < ln.start {
+				lo = ln.start
+			}
+			if hi > ln.end {
+				hi = ln.end
+			}
+			if lo < hi {
+				selX := x + (lo-ln.start)*gw*t.cfg.Scale
+				selW := (hi - lo) * gw * t.cfg.Scale
+				vector.DrawFilledRect(screen, float32(selX), float32(lineY), float32(selW), float32(lineHeight), color.RGBA{100, 150, 220, 110}, false)
+			}
+		}
+
+		f.DrawText(screen, string(t.value[ln.start:ln.end]), x, lineY, t.cfg.Scale, color.White)
+	}
+
+	if t.active && t.cursorVisible && caretLine >= 0 {
+		caretX := x + caretCol*gw*t.cfg.Scale
+		caretY := y + caretLine*lineHeight
+		vector.DrawFilledRect(screen, float32(caretX), float32(caretY), 2, float32(lineHeight), color.White, false)
+	}
+}
+
+// Height returns the pixel height Draw will occupy for the current text,
+// so callers can size a containing box before drawing into it.
+func (t *TextInput) Height() int {
+	_, gh := t.cfg.Font.GlyphSize()
+	return len(t.wrapLines()) * gh * t.cfg.Scale
+}